@@ -0,0 +1,90 @@
+package conflint
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+const checkstyleVersion = "4.3"
+
+// CheckstyleReporter prints a checkstyle XML document that groups diagnostics per file,
+// so that it can be consumed by e.g. Jenkins warnings-ng and `reviewdog -f=checkstyle`
+type CheckstyleReporter struct {
+}
+
+type checkstyleOutput struct {
+	XMLName xml.Name          `xml:"checkstyle"`
+	Version string            `xml:"version,attr"`
+	Files   []*checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+func (c *CheckstyleReporter) Report(w io.Writer, results []Result) error {
+	out := checkstyleOutput{
+		Version: checkstyleVersion,
+	}
+
+	files := map[string]*checkstyleFile{}
+
+	file := func(name string) *checkstyleFile {
+		f, ok := files[name]
+		if !ok {
+			f = &checkstyleFile{Name: name}
+			files[name] = f
+			out.Files = append(out.Files, f)
+		}
+
+		return f
+	}
+
+	for _, res := range results {
+		for _, name := range res.Files {
+			file(name)
+		}
+
+		for _, d := range res.Diagnostics {
+			f := file(d.File)
+
+			source := d.Linter
+			if d.Rule != "" {
+				source += "." + d.Rule
+			}
+
+			f.Errors = append(f.Errors, checkstyleError{
+				Line:     d.Line,
+				Column:   d.Column,
+				Severity: string(d.Severity),
+				Message:  d.Message,
+				Source:   source,
+			})
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("encoding checkstyle xml: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
@@ -0,0 +1,79 @@
+package conflint
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckstyleReporter(t *testing.T) {
+	results := []Result{
+		{
+			Linter: "conftest",
+			Files:  []string{"app1/nginx.deploy.yaml", "app1/nginx.svc.yaml"},
+			Diagnostics: []Diagnostic{
+				{
+					Linter:   "conftest",
+					Rule:     "deny",
+					Severity: SeverityError,
+					File:     "app1/nginx.deploy.yaml",
+					Line:     15,
+					Column:   11,
+					Message:  "`privileged: true` is forbidden",
+				},
+			},
+		},
+		{
+			Linter: "kubeval",
+			Files:  []string{"app1/nginx.deploy.yaml"},
+			Diagnostics: []Diagnostic{
+				{
+					Linter:   "kubeval",
+					Rule:     "schema",
+					Severity: SeverityError,
+					File:     "app1/nginx.deploy.yaml",
+					Line:     18,
+					Column:   25,
+					Message:  "Invalid type. Expected: [boolean,null], given: string",
+				},
+			},
+		},
+		{
+			Linter: "mylinter",
+			Files:  []string{"app1/nginx.svc.yaml"},
+			Diagnostics: []Diagnostic{
+				{
+					Linter:   "mylinter",
+					Severity: SeverityWarning,
+					File:     "app1/nginx.svc.yaml",
+					Line:     1,
+					Column:   1,
+					Message:  "services are discouraged",
+				},
+			},
+		},
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="app1/nginx.deploy.yaml">
+    <error line="15" column="11" severity="error" message="` + "`privileged: true` is forbidden" + `" source="conftest.deny"></error>
+    <error line="18" column="25" severity="error" message="Invalid type. Expected: [boolean,null], given: string" source="kubeval.schema"></error>
+  </file>
+  <file name="app1/nginx.svc.yaml">
+    <error line="1" column="1" severity="warning" message="services are discouraged" source="mylinter"></error>
+  </file>
+</checkstyle>
+`
+
+	buf := &bytes.Buffer{}
+
+	if err := (&CheckstyleReporter{}).Report(buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("unexpected output: %s", diff)
+	}
+}
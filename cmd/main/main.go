@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"runtime"
 
 	"github.com/mumoshu/conflint"
 )
@@ -41,28 +43,45 @@ func main() {
 	case CmdRun:
 		runCmd := flag.NewFlagSet(CmdRun, flag.ExitOnError)
 		configFile := runCmd.String("c", "conflint.yaml", "Configuration file to be loaded")
-		errformat := runCmd.String("efm", "%f:%l:%c: %m", "errorformat-style output format. Specify the same format to reviewdog for integration")
+		errformat := runCmd.String("efm", "%f:%l:%c: %m", "errorformat-style output format. Supported placeholders are %f(file), %l(line), %c(column), %L(end line), %C(end column), %s(severity) and %m(message). Specify the same format to reviewdog for integration")
+		format := runCmd.String("o", "efm", "Output format. One of efm, sarif, checkstyle, junit, rdjson and rdjsonl. efm prints every linter error in the format specified via -efm")
+		timeout := runCmd.Duration("timeout", 0, "Maximum duration of the whole run like 5m. No timeout when zero")
+		concurrency := runCmd.Int("j", runtime.GOMAXPROCS(0), "Number of linter invocations to run in parallel")
+		firstMatch := runCmd.Bool("first-match", false, "Report only the first node when the jsonpath in a linter error matches many nodes, rather than one linter error per node")
+		strictPaths := runCmd.Bool("strict-paths", false, "Fail when the jsonpath in a linter error has no node in the file, rather than reporting the linter error at the nearest existing parent node")
+		anchorName := runCmd.String("anchor", "value", "Part of the YAML node located by a jsonpath that linter errors start at. Either key or value. Override it per config entry via anchor, or per linter error via a jsonpath suffix like @key")
 		delim := runCmd.String("d", ": ", "Delimiter between the jsonpath part and the message part. For a linter error `$.apiVersion| apiVersion must be apps/v1` and `-d '|'`, `$.apiVersion` is considered as the jsonpath part, and the `apiVersion must be apps/v1` as the message part")
 
 		if err := runCmd.Parse(os.Args[2:]); err != nil {
 			fatal("%v", err)
 		}
 
+		anchor, err := conflint.ParseAnchor(*anchorName)
+		if err != nil {
+			fatal("%v", err)
+		}
+
 		wd, err := os.Getwd()
 		if err != nil {
 			fatal("%v", err)
 		}
 
 		runner := &conflint.Runner{
-			ConfigFile: *configFile,
-			Errformat:  *errformat,
-			Output:     os.Stdout,
-			WorkDir:    wd,
-			Delim:      *delim,
-			LogLevel:   os.Getenv("CONFLINT_LOG"),
+			ConfigFile:     *configFile,
+			Errformat:      *errformat,
+			Output:         os.Stdout,
+			WorkDir:        wd,
+			Delim:          *delim,
+			Format:         *format,
+			Concurrency:    *concurrency,
+			Timeout:        *timeout,
+			FirstMatchOnly: *firstMatch,
+			StrictPaths:    *strictPaths,
+			Anchor:         anchor,
+			LogLevel:       os.Getenv("CONFLINT_LOG"),
 		}
 
-		if err := runner.Run(); err != nil {
+		if err := runner.Run(context.Background()); err != nil {
 			fatal("%v", err)
 		}
 	default:
@@ -29,9 +29,10 @@ func TestRun(t *testing.T) {
 		wantErr string
 	}{
 		{
-			dir:     "simple",
-			wantOut: "app1/nginx.deploy.yaml:15:11: `privileged: true` is forbidden\n",
-			wantErr: "Error: found 1 linter error\n",
+			dir: "simple",
+			wantOut: "app1/nginx.deploy.yaml:1:13: Too old apiVersion. It must be apps/v1\n" +
+				"app1/nginx.deploy.yaml:15:11: `privileged: true` is forbidden\n",
+			wantErr: "Error: found 1 linter error and 1 warning\n",
 		},
 		{
 			dir:     "kubeval-fail",
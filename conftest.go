@@ -0,0 +1,160 @@
+package conflint
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+type ConftestConfig struct {
+	EntryConfig `yaml:",inline"`
+
+	Files         []string `yaml:"files"`
+	Policy        string   `yaml:"policy"`
+	Input         string   `yaml:"input"`
+	Combine       bool     `yaml:"combine"`
+	Data          []string `yaml:"data"`
+	AllNamespaces bool     `yaml:"allNamespaces"`
+	Namespaces    []string `yaml:"namespaces"`
+}
+
+type ConftestOutput = []ConftestFileResult
+
+type ConftestFileResult struct {
+	Filename string           `yaml:"filename"`
+	Warnings []ConftestResult `yaml:"warnings"`
+	Failures []ConftestResult `yaml:"failures"`
+}
+
+type ConftestResult struct {
+	Msg string `yaml:"msg"`
+	// Metadata is the rest of the object returned by the policy rule, along with `msg`.
+	// `kind`, `namespace` and `name` in it select the document to locate the message in, within a multi-document file.
+	Metadata map[string]interface{} `yaml:"metadata,omitempty"`
+	// Document is the zero-based position of the document the message is about, within a multi-document file.
+	// conftest doesn't report it, but RegoLinter does.
+	Document *int `yaml:"document,omitempty"`
+}
+
+// DocumentSelector returns the selector for the document the result is about
+func (c ConftestResult) DocumentSelector() DocumentSelector {
+	str := func(key string) string {
+		s, _ := c.Metadata[key].(string)
+		return s
+	}
+
+	return DocumentSelector{
+		Index:     c.Document,
+		Kind:      str("kind"),
+		Namespace: str("namespace"),
+		Name:      str("name"),
+	}
+}
+
+// ConftestLinter runs conftest against files matching each pattern in Files.
+//
+// Every conftest policy message is expected to start with a jsonpath expression followed by the runner's Delim,
+// so that the message can be located in the file.
+type ConftestLinter struct {
+	ConftestConfig
+}
+
+func (c *ConftestLinter) DecodeConfig(node *yaml.Node) error {
+	return node.Decode(&c.ConftestConfig)
+}
+
+func (c *ConftestLinter) Expand(r *Runner) ([][]string, error) {
+	return globGroups(r.WorkDir, c.Files, false)
+}
+
+func (c *ConftestLinter) Exec(ctx context.Context, r *Runner, files []string) ([]byte, error) {
+	args := []string{"test"}
+	args = append(args, files...)
+	args = append(args, "-p", c.Policy, "-o", "json")
+
+	if c.Input != "" {
+		args = append(args, "-i", c.Input)
+	}
+	if c.Combine {
+		args = append(args, "--combine")
+	}
+	if c.AllNamespaces {
+		args = append(args, "--all-namespaces")
+	}
+	if len(c.Data) > 0 {
+		args = append(args, "--data", strings.Join(c.Data, ","))
+	}
+	if len(c.Namespaces) > 0 {
+		args = append(args, "--namespace", strings.Join(c.Namespaces, ","))
+	}
+
+	return execCommand(ctx, r, "conftest", args...)
+}
+
+func (c *ConftestLinter) Diagnostics(r *Runner, files []string, out []byte) ([]Diagnostic, error) {
+	var conftestOut ConftestOutput
+
+	if err := yaml.Unmarshal(out, &conftestOut); err != nil {
+		return nil, err
+	}
+
+	return conftestDiagnostics(r, "conftest", conftestOut)
+}
+
+// conftestDiagnostics locates messages in the output of `conftest test -o json` and converts them into diagnostics
+func conftestDiagnostics(r *Runner, linter string, conftestOut ConftestOutput) ([]Diagnostic, error) {
+	var diags []Diagnostic
+
+	for _, res := range conftestOut {
+		handle := func(result ConftestResult, rule string, severity Severity) error {
+			msg := result.Msg
+			sub := strings.SplitN(msg, r.Delim, 2)
+			if len(sub) > 1 {
+				ds, err := r.LocateInDocument(res.Filename, result.DocumentSelector(), messageJsonpath(sub[0]))
+				if err != nil {
+					return fmt.Errorf("processing %s: %w", sub[0], err)
+				}
+				for _, d := range ds {
+					d.Linter = linter
+					d.Rule = rule
+					d.Severity = severity
+					d.Message = sub[1]
+					diags = append(diags, d)
+				}
+			} else {
+				log.Printf("ignoring unsupported output: %s", msg)
+			}
+
+			return nil
+		}
+
+		for _, f := range res.Failures {
+			if err := handle(f, "deny", SeverityError); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, w := range res.Warnings {
+			if err := handle(w, "warn", SeverityWarning); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return diags, nil
+}
+
+// messageJsonpath returns the jsonpath expression for the path at the beginning of a message, like `spec.replicas` or `[0].name`
+func messageJsonpath(p string) string {
+	switch {
+	case strings.HasPrefix(p, "$"):
+		return p
+	case strings.HasPrefix(p, "["):
+		return "$" + p
+	}
+
+	return "$." + p
+}
@@ -0,0 +1,43 @@
+package conflint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConftestDiagnosticsDocument(t *testing.T) {
+	r := &Runner{
+		WorkDir: filepath.Join("testdata", "multi-document"),
+		Delim:   ": ",
+	}
+
+	out := []byte(`[
+  {
+    "filename": "app1/resources.yaml",
+    "failures": [
+      {"msg": "metadata.name: forbidden name", "metadata": {"kind": "Service", "name": "web"}},
+      {"msg": "metadata.name: forbidden name"}
+    ]
+  }
+]`)
+
+	diags, err := (&ConftestLinter{}).Diagnostics(r, nil, out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got [][2]int
+
+	for _, d := range diags {
+		got = append(got, [2]int{d.Line, d.Column})
+	}
+
+	// The message without the metadata falls back to the first document having the path
+	want := [][2]int{{21, 9}, {4, 9}}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected positions: %s", diff)
+	}
+}
@@ -0,0 +1,405 @@
+package conflint
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+type CustomConfig struct {
+	EntryConfig `yaml:",inline"`
+
+	// Name is the name of the tool shown in diagnostics. Defaults to `custom`
+	Name string `yaml:"name"`
+	// Command is the command and its arguments. Every argument is a Go template with `.File` and `.Files`.
+	// An argument consisting only of `{{ .Files }}` expands to one argument per file.
+	// The files are appended to the command when no argument refers to them
+	Command []string `yaml:"command"`
+	Files   []string `yaml:"files"`
+	// PerFile runs the command once per file, rather than once per file pattern
+	PerFile bool `yaml:"perFile"`
+
+	// Errorformat is the list of errorformat patterns tried in order against every line of the output.
+	// Supported placeholders are %f, %l, %c, %t, %m and %%
+	Errorformat []string `yaml:"errorformat"`
+	// Regex is matched against every line of the output. Named groups file, line, col, path, message, severity and rule are used
+	Regex string `yaml:"regex"`
+	// JSON maps fields in the JSON output to diagnostics
+	JSON *CustomJSONMapping `yaml:"json"`
+}
+
+// CustomJSONMapping is the set of jsonpath expressions to extract diagnostics from the JSON output.
+// Items is evaluated against the whole output, and the rest are evaluated against each item
+type CustomJSONMapping struct {
+	Items    string `yaml:"items"`
+	File     string `yaml:"file"`
+	Line     string `yaml:"line"`
+	Column   string `yaml:"column"`
+	Path     string `yaml:"path"`
+	Message  string `yaml:"message"`
+	Severity string `yaml:"severity"`
+	Rule     string `yaml:"rule"`
+}
+
+// CustomLinter runs an arbitrary command and parses its output as configured
+type CustomLinter struct {
+	CustomConfig
+
+	command []*template.Template
+	// patterns is the list of regular expressions with named groups, compiled from either Errorformat or Regex
+	patterns []*regexp.Regexp
+}
+
+// customFilesArg matches the argument that expands to all the files
+var customFilesArg = regexp.MustCompile(`^{{-?\s*\.Files\s*-?}}$`)
+
+func (c *CustomLinter) DecodeConfig(node *yaml.Node) error {
+	if err := node.Decode(&c.CustomConfig); err != nil {
+		return err
+	}
+
+	if c.Name == "" {
+		c.Name = "custom"
+	}
+
+	if len(c.Command) == 0 {
+		return fmt.Errorf("line %d: command is required", node.Line)
+	}
+
+	for i, arg := range c.Command {
+		tmpl, err := template.New(fmt.Sprintf("command[%d]", i)).Parse(arg)
+		if err != nil {
+			return fmt.Errorf("line %d: parsing command: %w", node.Line, err)
+		}
+
+		c.command = append(c.command, tmpl)
+	}
+
+	var parsers int
+
+	if len(c.Errorformat) > 0 {
+		parsers++
+
+		for _, efm := range c.Errorformat {
+			re, err := errorformatRegexp(efm)
+			if err != nil {
+				return fmt.Errorf("line %d: parsing errorformat %q: %w", node.Line, efm, err)
+			}
+
+			c.patterns = append(c.patterns, re)
+		}
+	}
+
+	if c.Regex != "" {
+		parsers++
+
+		re, err := regexp.Compile(c.Regex)
+		if err != nil {
+			return fmt.Errorf("line %d: parsing regex: %w", node.Line, err)
+		}
+
+		c.patterns = append(c.patterns, re)
+	}
+
+	if c.JSON != nil {
+		parsers++
+
+		if c.JSON.Items == "" {
+			c.JSON.Items = "$"
+		}
+
+		for _, expr := range []string{c.JSON.Items, c.JSON.File, c.JSON.Line, c.JSON.Column, c.JSON.Path, c.JSON.Message, c.JSON.Severity, c.JSON.Rule} {
+			if expr == "" {
+				continue
+			}
+
+			if _, err := parseJsonpath(expr); err != nil {
+				return fmt.Errorf("line %d: parsing json mapping: %w", node.Line, err)
+			}
+		}
+	}
+
+	if parsers != 1 {
+		return fmt.Errorf("line %d: exactly one of errorformat, regex and json must be specified", node.Line)
+	}
+
+	return nil
+}
+
+func (c *CustomLinter) Expand(r *Runner) ([][]string, error) {
+	return globGroups(r.WorkDir, c.Files, c.PerFile)
+}
+
+func (c *CustomLinter) Exec(ctx context.Context, r *Runner, files []string) ([]byte, error) {
+	data := struct {
+		File  string
+		Files []string
+	}{
+		File:  files[0],
+		Files: files,
+	}
+
+	var (
+		args        []string
+		refersFiles bool
+	)
+
+	for i, tmpl := range c.command {
+		if customFilesArg.MatchString(c.Command[i]) {
+			args = append(args, files...)
+			refersFiles = true
+
+			continue
+		}
+
+		if strings.Contains(c.Command[i], ".File") {
+			refersFiles = true
+		}
+
+		var buf bytes.Buffer
+
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("rendering command: %w", err)
+		}
+
+		args = append(args, buf.String())
+	}
+
+	if !refersFiles {
+		args = append(args, files...)
+	}
+
+	return execCommand(ctx, r, args[0], args[1:]...)
+}
+
+func (c *CustomLinter) Diagnostics(r *Runner, files []string, out []byte) ([]Diagnostic, error) {
+	var (
+		fields []map[string]string
+		err    error
+	)
+
+	if c.JSON != nil {
+		fields, err = c.parseJSON(out)
+	} else {
+		fields = c.parseLines(out)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var diags []Diagnostic
+
+	for _, f := range fields {
+		file := f["file"]
+
+		switch {
+		case file == "" && len(files) == 1:
+			file = files[0]
+		case file == "":
+			log.Printf("ignoring %s output without file: %s", c.Name, f["message"])
+
+			continue
+		case filepath.IsAbs(file):
+			if rel, err := filepath.Rel(r.WorkDir, file); err == nil {
+				file = rel
+			}
+		}
+
+		ds := []Diagnostic{{File: file}}
+
+		if path := f["path"]; path != "" {
+			path = messageJsonpath(path)
+
+			ds, err = r.Locate(file, path)
+			if err != nil {
+				return nil, fmt.Errorf("processing %s: %w", path, err)
+			}
+		} else {
+			ds[0].Line, _ = strconv.Atoi(f["line"])
+			ds[0].Column, _ = strconv.Atoi(f["col"])
+
+			// Positions are 1-based, so anything missing or unparsable falls back to the start of the file or the line
+			if ds[0].Line < 1 {
+				ds[0].Line, ds[0].Column = 1, 1
+				ds[0].Approximate = true
+			} else if ds[0].Column < 1 {
+				ds[0].Column = 1
+				ds[0].Approximate = true
+			}
+		}
+
+		rule := f["rule"]
+		if rule == "" {
+			rule = c.Name
+		}
+
+		for _, d := range ds {
+			d.Linter = c.Name
+			d.Rule = rule
+			d.Severity = customSeverity(f["severity"])
+			d.Message = f["message"]
+
+			diags = append(diags, d)
+		}
+	}
+
+	return diags, nil
+}
+
+// parseLines returns named groups of the first pattern matching each line of the output
+func (c *CustomLinter) parseLines(out []byte) []map[string]string {
+	var res []map[string]string
+
+	lines := bufio.NewScanner(bytes.NewReader(out))
+	for lines.Scan() {
+		line := lines.Text()
+
+		for _, re := range c.patterns {
+			m := re.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+
+			fields := map[string]string{}
+
+			for i, name := range re.SubexpNames() {
+				if name != "" {
+					fields[name] = m[i]
+				}
+			}
+
+			res = append(res, fields)
+
+			break
+		}
+	}
+
+	return res
+}
+
+// parseJSON returns fields of each item in the output, as specified in the JSON mapping
+func (c *CustomLinter) parseJSON(out []byte) ([]map[string]string, error) {
+	var doc yaml.Node
+
+	// JSON is a subset of YAML, so that we can reuse the jsonpath implementation for YAML nodes
+	if err := yaml.Unmarshal(out, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshalling %s output: %w", c.Name, err)
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	items, err := jsonpathGet(doc.Content[0], c.JSON.Items)
+	if err != nil {
+		return nil, fmt.Errorf("getting items from %s output: %w", c.Name, err)
+	}
+
+	// Items can be either the array of items like `$.results`, or the items themselves like `$.results[*]`
+	if len(items) == 1 && items[0].Kind == yaml.SequenceNode {
+		items = items[0].Content
+	}
+
+	mapping := map[string]string{
+		"file":     c.JSON.File,
+		"line":     c.JSON.Line,
+		"col":      c.JSON.Column,
+		"path":     c.JSON.Path,
+		"message":  c.JSON.Message,
+		"severity": c.JSON.Severity,
+		"rule":     c.JSON.Rule,
+	}
+
+	var res []map[string]string
+
+	for _, item := range items {
+		fields := map[string]string{}
+
+		for name, expr := range mapping {
+			if expr == "" {
+				continue
+			}
+
+			// Fields missing in the item are left empty, as tools usually omit optional fields
+			if nodes, err := jsonpathGet(item, expr); err == nil {
+				fields[name] = nodes[0].Value
+			}
+		}
+
+		res = append(res, fields)
+	}
+
+	return res, nil
+}
+
+// jsonpathGet returns the nodes at the jsonpath expression. `$` refers to the node itself
+func jsonpathGet(node *yaml.Node, expr string) ([]*yaml.Node, error) {
+	path, err := parseJsonpath(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing jsonpath %s: %w", expr, err)
+	}
+
+	return path.Get(node)
+}
+
+// customSeverity converts severities like `error`, `W` and `warn` into Severity, defaulting to SeverityError
+func customSeverity(s string) Severity {
+	switch strings.ToLower(s) {
+	case "w", "warn", "warning":
+		return SeverityWarning
+	default:
+		return SeverityError
+	}
+}
+
+// errorformatRegexp converts the errorformat pattern like `%f:%l:%c: %m` into the regular expression with named groups
+func errorformatRegexp(efm string) (*regexp.Regexp, error) {
+	var b strings.Builder
+
+	b.WriteString("^")
+
+	for i := 0; i < len(efm); i++ {
+		if efm[i] != '%' {
+			b.WriteString(regexp.QuoteMeta(efm[i : i+1]))
+
+			continue
+		}
+
+		i++
+
+		if i == len(efm) {
+			return nil, fmt.Errorf("unexpected end of pattern after %%")
+		}
+
+		switch efm[i] {
+		case 'f':
+			b.WriteString(`(?P<file>.+?)`)
+		case 'l':
+			b.WriteString(`(?P<line>\d+)`)
+		case 'c':
+			b.WriteString(`(?P<col>\d+)`)
+		case 't':
+			b.WriteString(`(?P<severity>[a-zA-Z])`)
+		case 'm':
+			b.WriteString(`(?P<message>.*)`)
+		case '%':
+			b.WriteString("%")
+		default:
+			return nil, fmt.Errorf("unsupported placeholder %%%c", efm[i])
+		}
+	}
+
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,147 @@
+package conflint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestCustomJSONDiagnostics(t *testing.T) {
+	config := `
+name: mytool
+command: [mytool, --json]
+json:
+  items: $.results
+  file: $.filename
+  path: $.location
+  message: $.text
+  severity: $.level
+  rule: $.id
+`
+
+	out := `{
+  "results": [
+    {"filename": "app1/nginx.deploy.yaml", "location": "$.metadata.name", "text": "name is reserved", "level": "warn", "id": "reserved-name"},
+    {"filename": "app1/nginx.deploy.yaml", "location": "spec.template.spec.containers[0].securityContext.privileged", "text": "privileged containers are forbidden"}
+  ]
+}`
+
+	var node yaml.Node
+
+	if err := yaml.Unmarshal([]byte(config), &node); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &CustomLinter{}
+
+	if err := c.DecodeConfig(node.Content[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	runner := &Runner{WorkDir: filepath.Join("testdata", "custom")}
+
+	diags, err := c.Diagnostics(runner, []string{"app1/nginx.deploy.yaml"}, []byte(out))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Diagnostic{
+		{Linter: "mytool", Rule: "reserved-name", Severity: SeverityWarning, File: "app1/nginx.deploy.yaml", Line: 4, Column: 9, EndLine: 4, EndColumn: 14, Message: "name is reserved"},
+		{Linter: "mytool", Rule: "mytool", Severity: SeverityError, File: "app1/nginx.deploy.yaml", Line: 18, Column: 25, EndLine: 18, EndColumn: 29, Message: "privileged containers are forbidden"},
+	}
+
+	if d := cmp.Diff(want, diags); d != "" {
+		t.Errorf("unexpected diagnostics: want (-), got (+):\n%s", d)
+	}
+}
+
+func TestErrorformatRegexp(t *testing.T) {
+	testcases := []struct {
+		efm  string
+		line string
+		want map[string]string
+	}{
+		{
+			efm:  "%f:%l:%c: %m",
+			line: "app1/a.yaml:3:5: bad value",
+			want: map[string]string{"file": "app1/a.yaml", "line": "3", "col": "5", "message": "bad value"},
+		},
+		{
+			efm:  "[%t] %f(%l): 100%% %m",
+			line: "[W] app1/a.yaml(10): 100% sure",
+			want: map[string]string{"severity": "W", "file": "app1/a.yaml", "line": "10", "message": "sure"},
+		},
+	}
+
+	for _, tc := range testcases {
+		re, err := errorformatRegexp(tc.efm)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		m := re.FindStringSubmatch(tc.line)
+		if m == nil {
+			t.Fatalf("%q didn't match %q", tc.efm, tc.line)
+		}
+
+		got := map[string]string{}
+
+		for i, name := range re.SubexpNames() {
+			if name != "" {
+				got[name] = m[i]
+			}
+		}
+
+		if d := cmp.Diff(tc.want, got); d != "" {
+			t.Errorf("unexpected match for %q: want (-), got (+):\n%s", tc.efm, d)
+		}
+	}
+
+	if _, err := errorformatRegexp("%f:%x"); err == nil {
+		t.Error("expected error for unsupported placeholder")
+	}
+}
+
+func TestCustomDiagnosticsPositions(t *testing.T) {
+	config := `
+name: mytool
+command: [mytool]
+regex: '^(?P<file>[^:]+):(?P<line>\d*):(?P<path>\S*): (?P<message>.*)$'
+`
+
+	out := `app1/resources.yaml::[0].privileged: privileged containers are forbidden
+app1/resources.yaml:8:: configmaps are discouraged
+app1/resources.yaml::: file is too long
+`
+
+	var node yaml.Node
+
+	if err := yaml.Unmarshal([]byte(config), &node); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &CustomLinter{}
+
+	if err := c.DecodeConfig(node.Content[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	runner := &Runner{WorkDir: filepath.Join("testdata", "non-mapping")}
+
+	diags, err := c.Diagnostics(runner, []string{"app1/resources.yaml"}, []byte(out))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Diagnostic{
+		{Linter: "mytool", Rule: "mytool", Severity: SeverityError, File: "app1/resources.yaml", Line: 4, Column: 15, EndLine: 4, EndColumn: 19, Message: "privileged containers are forbidden"},
+		{Linter: "mytool", Rule: "mytool", Severity: SeverityError, File: "app1/resources.yaml", Line: 8, Column: 1, Message: "configmaps are discouraged", Approximate: true},
+		{Linter: "mytool", Rule: "mytool", Severity: SeverityError, File: "app1/resources.yaml", Line: 1, Column: 1, Message: "file is too long", Approximate: true},
+	}
+
+	if d := cmp.Diff(want, diags); d != "" {
+		t.Errorf("unexpected diagnostics: want (-), got (+):\n%s", d)
+	}
+}
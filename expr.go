@@ -2,6 +2,8 @@ package conflint
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	yaml "gopkg.in/yaml.v3"
@@ -64,12 +66,39 @@ func (e *CondOpAnd) String() string {
 	all := []string{}
 
 	for _, expr := range e.Exprs {
-		all = append(all, expr.String())
+		if _, ok := expr.(*CondOpOr); ok {
+			all = append(all, "("+expr.String()+")")
+		} else {
+			all = append(all, expr.String())
+		}
 	}
 
 	return strings.Join(all, " && ")
 }
 
+// ExprNot negates the expression, like `!@.resources`
+type ExprNot struct {
+	Expr BoolExpr
+}
+
+func (e *ExprNot) Eval(ctx Context) (bool, error) {
+	r, err := e.Expr.Eval(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return !r, nil
+}
+
+func (e *ExprNot) String() string {
+	switch e.Expr.(type) {
+	case *ExprExists, *ExprNot:
+		return "!" + e.Expr.String()
+	}
+
+	return "!(" + e.Expr.String() + ")"
+}
+
 type ExprEq struct {
 	Path  *Path
 	Value string
@@ -83,10 +112,116 @@ func (e *ExprEq) String() string {
 func (e *ExprEq) Eval(ctx Context) (bool, error) {
 	got, err := e.Path.Get(ctx.Current)
 	if err != nil {
-		return false, fmt.Errorf("evaluating `%s`: %w", e.Code, err)
+		// Nothing is equal to the missing value, so that elements missing the field just don't match
+		return false, nil
+	}
+
+	return scalarEquals(got[0], e.Value), nil
+}
+
+// ExprNotEq is true when the value at the path is missing or not equal to the value, like `@.name != 'sidecar'`
+type ExprNotEq struct {
+	Path  *Path
+	Value string
+	Code  string
+}
+
+func (e *ExprNotEq) String() string {
+	return e.Code
+}
+
+func (e *ExprNotEq) Eval(ctx Context) (bool, error) {
+	got, err := e.Path.Get(ctx.Current)
+	if err != nil {
+		return true, nil
+	}
+
+	return !scalarEquals(got[0], e.Value), nil
+}
+
+// ExprCompare compares the value at the path with the value by one of `<`, `<=`, `>` and `>=`, like `@.replicas > 3`.
+// Values are compared as numbers when both are numbers, or as strings otherwise
+type ExprCompare struct {
+	Path  *Path
+	Op    string
+	Value string
+	Code  string
+}
+
+func (e *ExprCompare) String() string {
+	return e.Code
+}
+
+func (e *ExprCompare) Eval(ctx Context) (bool, error) {
+	got, err := e.Path.Get(ctx.Current)
+	if err != nil || got[0].Kind != yaml.ScalarNode {
+		return false, nil
+	}
+
+	var cmp int
+
+	l, lerr := strconv.ParseFloat(got[0].Value, 64)
+	r, rerr := strconv.ParseFloat(e.Value, 64)
+
+	switch {
+	case lerr == nil && rerr == nil && l < r:
+		cmp = -1
+	case lerr == nil && rerr == nil && l > r:
+		cmp = 1
+	case lerr == nil && rerr == nil:
+		cmp = 0
+	default:
+		cmp = strings.Compare(got[0].Value, e.Value)
 	}
 
-	return got.Value == e.Value, nil
+	switch e.Op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	}
+
+	return false, fmt.Errorf("unsupported operator %s", e.Op)
+}
+
+// ExprMatch is true when the value at the path matches the regular expression, like `@.image =~ /:latest$/`
+type ExprMatch struct {
+	Path   *Path
+	Regexp *regexp.Regexp
+	Code   string
+}
+
+func (e *ExprMatch) String() string {
+	return e.Code
+}
+
+func (e *ExprMatch) Eval(ctx Context) (bool, error) {
+	got, err := e.Path.Get(ctx.Current)
+	if err != nil || got[0].Kind != yaml.ScalarNode {
+		return false, nil
+	}
+
+	return e.Regexp.MatchString(got[0].Value), nil
+}
+
+// ExprExists is true when the path has any value, like `@.resources`
+type ExprExists struct {
+	Path *Path
+	Code string
+}
+
+func (e *ExprExists) String() string {
+	return e.Code
+}
+
+func (e *ExprExists) Eval(ctx Context) (bool, error) {
+	_, err := e.Path.Get(ctx.Current)
+
+	return err == nil, nil
 }
 
 type BoolExpr interface {
@@ -94,74 +229,439 @@ type BoolExpr interface {
 	String() string
 }
 
-var _ BoolExpr = &ExprEq{}
+var (
+	_ BoolExpr = &ExprEq{}
+	_ BoolExpr = &ExprNotEq{}
+	_ BoolExpr = &ExprCompare{}
+	_ BoolExpr = &ExprMatch{}
+	_ BoolExpr = &ExprExists{}
+	_ BoolExpr = &ExprNot{}
+)
 
-func parseBoolExp(condStr string) (BoolExpr, error) {
-	var any []BoolExpr
+// scalarEquals returns true when the node is the scalar having the value. `null` matches any null
+func scalarEquals(node *yaml.Node, value string) bool {
+	if node.Kind != yaml.ScalarNode {
+		return false
+	}
 
-	anyComps := strings.Split(condStr, "||")
-	for i, anyComp := range anyComps {
+	if value == "null" && node.Tag == "!!null" {
+		return true
+	}
 
-		var all []BoolExpr
+	return node.Value == value
+}
 
-		andComps := strings.Split(anyComp, "&&")
-		for _, andComp := range andComps {
-			comp := strings.Split(andComp, "==")
-			if len(comp) != 2 {
-				return nil, fmt.Errorf("unsupported expression found: wanted an equality expression like `A == B`, but got %v", condStr)
-			}
+type tokenKind int
 
-			left := strings.TrimSpace(comp[0])
-			right := strings.TrimSpace(comp[1])
+const (
+	tokenPath tokenKind = iota
+	tokenString
+	tokenNumber
+	tokenLiteral
+	tokenRegexp
+	tokenOp
+	tokenEOF
+)
 
-			var rightValue string
+type token struct {
+	kind tokenKind
+	// text is the token as written in the expression
+	text string
+	// value is the string with quotes removed and escape sequences replaced, or the same as text for other kinds
+	value string
+	pos   int
+}
 
-			switch right[0] {
-			case '"':
-				rightValue = strings.TrimRight(right[1:], "\"")
-			case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-				rightValue = right
-			case '\'':
-				rightValue = strings.TrimRight(right[1:], "'")
-			default:
-				switch right {
-				case "true", "false":
-					rightValue = right
-				default:
-					return nil, fmt.Errorf("parsing right side of equality: unexpected expression: %v", right)
+// tokenizeBoolExp splits the filter expression into tokens like `@.name`, `==` and `'nginx'`
+func tokenizeBoolExp(expr string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '@' || c == '$':
+			j := pathEnd(expr, i)
+
+			tokens = append(tokens, token{kind: tokenPath, text: expr[i:j], value: expr[i:j], pos: i})
+
+			i = j
+		case c == '\'' || c == '"':
+			j := i + 1
+
+			for j < len(expr) && expr[j] != c {
+				if expr[j] == '\\' {
+					j++
 				}
+
+				j++
+			}
+
+			if j >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
 			}
 
-			nestedPath, err := parseJsonpath(left)
+			s, err := unquote(expr[i : j+1])
 			if err != nil {
-				return nil, fmt.Errorf("parsing %s: %w", left, err)
+				return nil, err
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: expr[i : j+1], value: s, pos: i})
+
+			i = j + 1
+		case c == '/':
+			j := i + 1
+
+			for j < len(expr) && expr[j] != '/' {
+				if expr[j] == '\\' && j+1 < len(expr) && expr[j+1] == '/' {
+					j++
+				}
+
+				j++
+			}
+
+			if j >= len(expr) {
+				return nil, fmt.Errorf("unterminated regular expression at %d", i)
+			}
+
+			// Flags like `i` follow the closing slash
+			k := j + 1
+			for k < len(expr) && strings.IndexByte("imsU", expr[k]) >= 0 {
+				k++
+			}
+
+			tokens = append(tokens, token{kind: tokenRegexp, text: expr[i:k], value: expr[i:k], pos: i})
+
+			i = k
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i + 1
+
+			for j < len(expr) && strings.IndexByte("0123456789.eE+-", expr[j]) >= 0 {
+				j++
+			}
+
+			if _, err := strconv.ParseFloat(expr[i:j], 64); err != nil {
+				return nil, fmt.Errorf("invalid number %s at %d", expr[i:j], i)
+			}
+
+			tokens = append(tokens, token{kind: tokenNumber, text: expr[i:j], value: expr[i:j], pos: i})
+
+			i = j
+		default:
+			var op string
+
+			for _, o := range []string{"&&", "||", "==", "!=", "=~", "<=", ">=", "<", ">", "!", "(", ")"} {
+				if strings.HasPrefix(expr[i:], o) {
+					op = o
+					break
+				}
+			}
+
+			if op != "" {
+				tokens = append(tokens, token{kind: tokenOp, text: op, value: op, pos: i})
+
+				i += len(op)
+
+				continue
 			}
 
-			boolExp := &ExprEq{
-				Path:  nestedPath,
-				Value: rightValue,
-				Code:  condStr,
+			j := i
+
+			for j < len(expr) && (expr[j] >= 'a' && expr[j] <= 'z') {
+				j++
 			}
 
-			all = append(all, boolExp)
+			switch expr[i:j] {
+			case "true", "false", "null":
+				tokens = append(tokens, token{kind: tokenLiteral, text: expr[i:j], value: expr[i:j], pos: i})
+
+				i = j
+			default:
+				return nil, fmt.Errorf("unexpected character %c at %d", c, i)
+			}
 		}
+	}
 
-		if len(all) == 0 {
-			return nil, fmt.Errorf("parsing and components from `%s` at %d", anyComp, i)
-		} else if len(all) == 1 {
-			any = append(any, all[0])
-		} else {
-			any = append(any, &CondOpAnd{
-				Exprs: all,
-			})
+	return append(tokens, token{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+// pathEnd returns the index right after the path starting at start within the filter expression
+func pathEnd(expr string, start int) int {
+	depth := 0
+
+	var quote byte
+
+	for i := start; i < len(expr); i++ {
+		c := expr[i]
+
+		if quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case quote:
+				quote = 0
+			}
+
+			continue
+		}
+
+		switch {
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth == 0 && strings.IndexByte(" \t=!<>&|)", c) >= 0:
+			return i
+		}
+	}
+
+	return len(expr)
+}
+
+// boolExpParser is a recursive descent parser of filter expressions:
+//
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" or ")" | comparison
+//	comparison = operand [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) operand | "=~" regexp ]
+type boolExpParser struct {
+	expr   string
+	tokens []token
+	pos    int
+}
+
+func (p *boolExpParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *boolExpParser) next() token {
+	t := p.tokens[p.pos]
+
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *boolExpParser) isOp(op string) bool {
+	t := p.peek()
+
+	return t.kind == tokenOp && t.text == op
+}
+
+func (p *boolExpParser) parseOr() (BoolExpr, error) {
+	var any []BoolExpr
+
+	for {
+		e, err := p.parseAnd()
+		if err != nil {
+			return nil, err
 		}
+
+		any = append(any, e)
+
+		if !p.isOp("||") {
+			break
+		}
+
+		p.next()
 	}
 
-	if len(any) == 0 {
-		return nil, fmt.Errorf("parsing or components from `%s`", condStr)
-	} else if len(any) == 1 {
+	if len(any) == 1 {
 		return any[0], nil
 	}
 
 	return &CondOpOr{Exprs: any}, nil
 }
+
+func (p *boolExpParser) parseAnd() (BoolExpr, error) {
+	var all []BoolExpr
+
+	for {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, e)
+
+		if !p.isOp("&&") {
+			break
+		}
+
+		p.next()
+	}
+
+	if len(all) == 1 {
+		return all[0], nil
+	}
+
+	return &CondOpAnd{Exprs: all}, nil
+}
+
+func (p *boolExpParser) parseUnary() (BoolExpr, error) {
+	switch {
+	case p.isOp("!"):
+		p.next()
+
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &ExprNot{Expr: e}, nil
+	case p.isOp("("):
+		p.next()
+
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.isOp(")") {
+			return nil, p.unexpected("`)`")
+		}
+
+		p.next()
+
+		return e, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *boolExpParser) parseComparison() (BoolExpr, error) {
+	left := p.peek()
+
+	if left.kind == tokenEOF || left.kind == tokenOp {
+		return nil, p.unexpected("a path like `@.name`")
+	}
+
+	p.next()
+
+	t := p.peek()
+
+	if t.kind != tokenOp || !isComparisonOp(t.text) {
+		if left.kind != tokenPath {
+			return nil, fmt.Errorf("unsupported expression `%s` at %d in `%s`: wanted a comparison or a path like `@.name`", left.text, left.pos, p.expr)
+		}
+
+		path, err := parseJsonpath(left.value)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", left.value, err)
+		}
+
+		return &ExprExists{Path: path, Code: left.text}, nil
+	}
+
+	op := p.next().text
+	right := p.next()
+
+	code := strings.TrimSpace(p.expr[left.pos:right.pos] + right.text)
+
+	if op == "=~" {
+		if left.kind != tokenPath || right.kind != tokenRegexp {
+			return nil, fmt.Errorf("unsupported expression `%s`: wanted a path on the left and a regular expression like /^nginx:/ on the right of =~", code)
+		}
+
+		path, err := parseJsonpath(left.value)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", left.value, err)
+		}
+
+		re, err := compileRegexpLiteral(right.value)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", right.value, err)
+		}
+
+		return &ExprMatch{Path: path, Regexp: re, Code: code}, nil
+	}
+
+	// Literals can be on either side
+	if left.kind != tokenPath && right.kind == tokenPath {
+		flipped := map[string]string{"==": "==", "!=": "!=", "<": ">", "<=": ">=", ">": "<", ">=": "<="}
+
+		left, right, op = right, left, flipped[op]
+	}
+
+	if left.kind != tokenPath {
+		return nil, fmt.Errorf("unsupported expression `%s`: wanted a path like `@.name` on either side", code)
+	}
+
+	switch right.kind {
+	case tokenString, tokenNumber, tokenLiteral:
+	default:
+		return nil, fmt.Errorf("unsupported expression `%s`: wanted a string, a number, true, false or null to compare with", code)
+	}
+
+	path, err := parseJsonpath(left.value)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", left.value, err)
+	}
+
+	switch op {
+	case "==":
+		return &ExprEq{Path: path, Value: right.value, Code: code}, nil
+	case "!=":
+		return &ExprNotEq{Path: path, Value: right.value, Code: code}, nil
+	}
+
+	return &ExprCompare{Path: path, Op: op, Value: right.value, Code: code}, nil
+}
+
+func (p *boolExpParser) unexpected(want string) error {
+	t := p.peek()
+
+	if t.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of expression in `%s`: wanted %s", p.expr, want)
+	}
+
+	return fmt.Errorf("unexpected `%s` at %d in `%s`: wanted %s", t.text, t.pos, p.expr, want)
+}
+
+func isComparisonOp(op string) bool {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=", "=~":
+		return true
+	}
+
+	return false
+}
+
+// compileRegexpLiteral compiles the regular expression literal like `/^nginx:/` and `/latest$/i`
+func compileRegexpLiteral(lit string) (*regexp.Regexp, error) {
+	end := strings.LastIndex(lit, "/")
+
+	pattern := strings.ReplaceAll(lit[1:end], `\/`, "/")
+
+	if flags := lit[end+1:]; flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+
+	return regexp.Compile(pattern)
+}
+
+func parseBoolExp(condStr string) (BoolExpr, error) {
+	tokens, err := tokenizeBoolExp(condStr)
+	if err != nil {
+		return nil, fmt.Errorf("tokenizing `%s`: %w", condStr, err)
+	}
+
+	p := &boolExpParser{expr: condStr, tokens: tokens}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected("`&&`, `||` or the end of expression")
+	}
+
+	return e, nil
+}
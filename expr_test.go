@@ -0,0 +1,90 @@
+package conflint
+
+import (
+	"fmt"
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestBoolExp(t *testing.T) {
+	data := `name: nginx
+image: nginx:latest
+replicas: 5
+resources:
+  limits:
+    cpu: 100m
+ports:
+- 80
+`
+
+	testcases := []struct {
+		expr string
+		want bool
+		str  string
+		err  string
+	}{
+		{expr: `@.name == 'nginx'`, want: true},
+		{expr: `@.name == "nginx"`, want: true},
+		{expr: `@.name != 'sidecar'`, want: true},
+		{expr: `@.missing != 'sidecar'`, want: true},
+		{expr: `@.missing == 'sidecar'`, want: false},
+		{expr: `@.replicas > 3`, want: true},
+		{expr: `@.replicas >= 5`, want: true},
+		{expr: `@.replicas < 5`, want: false},
+		{expr: `3 < @.replicas`, want: true},
+		{expr: `@.replicas <= -1`, want: false},
+		{expr: `@.image =~ /:latest$/`, want: true},
+		{expr: `@.image =~ /^NGINX/i`, want: true},
+		{expr: `@.image =~ /^envoy/`, want: false},
+		{expr: `@.resources`, want: true},
+		{expr: `!@.resources`, want: false},
+		{expr: `@.resources.limits.memory`, want: false},
+		{expr: `@.ports[0] == 80`, want: true},
+		{expr: `@['name'] == 'nginx'`, want: true},
+		{expr: `@.name == 'sidecar' || @.replicas > 3 && @.image =~ /latest/`, want: true},
+		{expr: `(@.name == 'sidecar' || @.replicas > 3) && !(@.image =~ /latest/)`, want: false, str: `(@.name == 'sidecar' || @.replicas > 3) && !(@.image =~ /latest/)`},
+		{expr: `@.name == `, err: "unsupported expression `@.name ==`: wanted a string, a number, true, false or null to compare with"},
+		{expr: `(@.name == 'nginx'`, err: "unexpected end of expression in `(@.name == 'nginx'`: wanted `)`"},
+		{expr: `'nginx'`, err: "unsupported expression `'nginx'` at 0 in `'nginx'`: wanted a comparison or a path like `@.name`"},
+		{expr: `@.name == 'nginx' @.image`, err: "unexpected `@.image` at 18 in `@.name == 'nginx' @.image`: wanted `&&`, `||` or the end of expression"},
+	}
+
+	var root yaml.Node
+
+	if err := yaml.Unmarshal([]byte(data), &root); err != nil {
+		t.Fatal("bug: failed parsing yaml")
+	}
+
+	for i := range testcases {
+		tc := testcases[i]
+
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			e, err := parseBoolExp(tc.expr)
+			if err != nil {
+				if tc.err == "" {
+					t.Fatalf("unexpected error: %v", err)
+				} else if err.Error() != tc.err {
+					t.Fatalf("unexpected error: want %q, got %q", tc.err, err.Error())
+				}
+
+				return
+			} else if tc.err != "" {
+				t.Fatalf("expected error: want %q, got none", tc.err)
+			}
+
+			got, err := e.Eval(Context{Current: root.Content[0]})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("unexpected result of `%s`: want %v, got %v", tc.expr, tc.want, got)
+			}
+
+			if tc.str != "" && e.String() != tc.str {
+				t.Errorf("unexpected string: want %q, got %q", tc.str, e.String())
+			}
+		})
+	}
+}
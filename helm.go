@@ -0,0 +1,212 @@
+package conflint
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+type HelmConfig struct {
+	EntryConfig `yaml:",inline"`
+
+	// Chart is the path to the chart directory
+	Chart string `yaml:"chart"`
+	// ReleaseName is the release name passed to `helm template`. Defaults to `release-name`
+	ReleaseName string   `yaml:"releaseName"`
+	Namespace   string   `yaml:"namespace"`
+	ValuesFiles []string `yaml:"valuesFiles"`
+	// Set is the list of values like `image.tag=1.0.0` passed via `--set`
+	Set []string `yaml:"set"`
+	// Linters is the config of the linters run against the rendered manifests, in the same format as the top-level config
+	Linters yaml.Node `yaml:"linters"`
+}
+
+// HelmLinter renders the chart with `helm template` and runs the nested linters against the rendered manifests.
+// Diagnostics are mapped back to the template files denoted by `# Source:` comments.
+type HelmLinter struct {
+	HelmConfig
+}
+
+// helmSourceComment matches the comment helm adds to every rendered manifest, like `# Source: mychart/templates/deployment.yaml`
+var helmSourceComment = regexp.MustCompile(`^# Source: (.+)$`)
+
+func (h *HelmLinter) DecodeConfig(node *yaml.Node) error {
+	if err := node.Decode(&h.HelmConfig); err != nil {
+		return err
+	}
+
+	if h.Chart == "" {
+		return fmt.Errorf("line %d: chart is required", node.Line)
+	}
+
+	if h.ReleaseName == "" {
+		h.ReleaseName = "release-name"
+	}
+
+	if h.Linters.Kind == 0 {
+		return fmt.Errorf("line %d: linters is required", node.Line)
+	}
+
+	// Catch errors in the nested config before rendering anything
+	config, err := nestedConfig(&h.Linters, "", nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := decodeConfigNode(config); err != nil {
+		return fmt.Errorf("decoding linters: %w", err)
+	}
+
+	return nil
+}
+
+// Expand returns a single group of the files in the chart followed by the values files, which are the inputs to `helm template`.
+// The chart is rendered as a whole regardless of the files, which are used to report the linted files in formats like junit.
+func (h *HelmLinter) Expand(r *Runner) ([][]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(filepath.Join(r.WorkDir, h.Chart), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Type().IsRegular() {
+			rel, err := filepath.Rel(r.WorkDir, path)
+			if err != nil {
+				return err
+			}
+
+			files = append(files, rel)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading chart %s: %w", h.Chart, err)
+	}
+
+	return [][]string{append(files, h.ValuesFiles...)}, nil
+}
+
+func (h *HelmLinter) Lint(ctx context.Context, r *Runner, files []string) ([]Diagnostic, error) {
+	args := []string{"template", h.ReleaseName, h.Chart}
+	if h.Namespace != "" {
+		args = append(args, "--namespace", h.Namespace)
+	}
+	for _, f := range h.ValuesFiles {
+		args = append(args, "--values", f)
+	}
+	for _, s := range h.Set {
+		args = append(args, "--set", s)
+	}
+
+	out, err := renderCommand(ctx, r, "helm", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := splitHelmOutput(out)
+
+	diags, err := lintRendered(ctx, r, "helm", &h.Linters, rendered)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, d := range diags {
+		source := d.File
+
+		d.File = helmTemplateFile(h.Chart, source)
+
+		tmpl, err := os.ReadFile(filepath.Join(r.WorkDir, d.File))
+		if err != nil {
+			return nil, fmt.Errorf("reading template for %s: %w", source, err)
+		}
+
+		diags[i] = mapToTemplate(d, tmpl, rendered[source])
+	}
+
+	return diags, nil
+}
+
+// splitHelmOutput splits the output of `helm template` into manifests keyed by their source template paths
+func splitHelmOutput(out []byte) map[string][]byte {
+	rendered := map[string][]byte{}
+
+	var (
+		source string
+		buf    bytes.Buffer
+	)
+
+	flush := func() {
+		if source != "" {
+			if len(rendered[source]) > 0 {
+				rendered[source] = append(rendered[source], "---\n"...)
+			}
+
+			rendered[source] = append(rendered[source], buf.Bytes()...)
+		}
+
+		source = ""
+		buf.Reset()
+	}
+
+	lines := bufio.NewScanner(bytes.NewReader(out))
+	lines.Buffer(nil, len(out)+1)
+
+	for lines.Scan() {
+		line := lines.Text()
+
+		if line == "---" {
+			flush()
+
+			continue
+		}
+
+		if m := helmSourceComment.FindStringSubmatch(line); m != nil && source == "" {
+			source = m[1]
+		}
+
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+
+	flush()
+
+	return rendered
+}
+
+// helmTemplateFile returns the path to the template file from the source path like `mychart/templates/deployment.yaml`,
+// whose first path component is the chart name rather than the chart directory
+func helmTemplateFile(chart, source string) string {
+	if i := strings.Index(source, "/"); i >= 0 {
+		source = source[i+1:]
+	}
+
+	return filepath.Join(chart, source)
+}
+
+// mapToTemplate moves the diagnostic located in the rendered manifest to the template line that is likely to have rendered it.
+//
+// It prefers the template line identical to the rendered line, and then the line having the same key.
+// The nearest line to the rendered line wins when there are many.
+// The diagnostic is moved to the top of the template when nothing is found, like when the line is rendered from a helper template.
+func mapToTemplate(d Diagnostic, tmpl, rendered []byte) Diagnostic {
+	renderedLines := strings.Split(string(rendered), "\n")
+	tmplLines := strings.Split(string(tmpl), "\n")
+
+	line, col, ok := templatePosition(tmplLines, renderedLines, d.Line, d.Column)
+
+	d.Line, d.Column = line, col
+	d.EndLine, d.EndColumn = 0, 0
+	d.Approximate = d.Approximate || !ok
+
+	return d
+}
@@ -0,0 +1,77 @@
+package conflint
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHelm(t *testing.T) {
+	bin, err := filepath.Abs(filepath.Join("testdata", "helm", "bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tmp := t.TempDir()
+
+	t.Setenv("TMPDIR", tmp)
+
+	buf := &bytes.Buffer{}
+
+	runner := &Runner{
+		Output:     buf,
+		WorkDir:    filepath.Join("testdata", "helm"),
+		ConfigFile: "conflint.yaml",
+		Errformat:  "%f:%l:%c: %s: %m",
+		Delim:      ": ",
+	}
+
+	err = runner.Run(context.Background())
+	if err == nil || err.Error() != "found 1 linter error and 1 warning" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "charts/myapp/templates/deployment.yaml:1:13: warning: Too old apiVersion. It must be apps/v1\n" +
+		"charts/myapp/templates/deployment.yaml:11:11: error: `privileged: true` is forbidden\n"
+
+	if d := cmp.Diff(want, buf.String()); d != "" {
+		t.Errorf("unexpected output: want (-), got (+):\n%s", d)
+	}
+
+	tmps, err := filepath.Glob(filepath.Join(tmp, "conflint-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tmps) > 0 {
+		t.Errorf("temporary directories are left: %v", tmps)
+	}
+}
+
+func TestHelmExpand(t *testing.T) {
+	h := &HelmLinter{HelmConfig: HelmConfig{Chart: "charts/myapp", ValuesFiles: []string{"values.yaml"}}}
+
+	groups, err := h.Expand(&Runner{WorkDir: filepath.Join("testdata", "helm")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := [][]string{{
+		"charts/myapp/Chart.yaml",
+		"charts/myapp/templates/_helpers.tpl",
+		"charts/myapp/templates/deployment.yaml",
+		"charts/myapp/templates/service.yaml",
+		"charts/myapp/values.yaml",
+		"values.yaml",
+	}}
+
+	if d := cmp.Diff(want, groups); d != "" {
+		t.Errorf("unexpected groups: want (-), got (+):\n%s", d)
+	}
+}
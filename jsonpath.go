@@ -8,21 +8,31 @@ import (
 	yaml "gopkg.in/yaml.v3"
 )
 
+// Path is a parsed jsonpath expression.
+//
+// The syntax follows RFC 9535, supporting `.key`, `['key']`, `.*`, `..key`, `[N]` including negative indexes, `[start:end:step]`,
+// `[*]`, `[?(@...)]`, and lists of selectors like `[0,2]`.
+// `[*]?(@...)` and `.N` for array elements are also supported for compatibility.
 type Path struct {
 	Getter []Getter
 }
 
+// Getter is a segment of a jsonpath expression, like `.spec` and `[*]`
 type Getter struct {
-	Get  func(node *yaml.Node) (*yaml.Node, error)
+	// Get returns nodes selected from the node, in the document order.
+	// It returns an error describing why when nothing is selected from the node.
+	Get  func(node *yaml.Node) ([]*yaml.Node, error)
 	Expr string
 }
 
 func yamlMapGet(key string) Getter {
 	return Getter{
 		Expr: "." + key,
-		Get: func(node *yaml.Node) (*yaml.Node, error) {
+		Get: func(node *yaml.Node) ([]*yaml.Node, error) {
 			var found *yaml.Node
 
+			node = resolveAlias(node)
+
 			switch node.Kind {
 			case yaml.MappingNode:
 				for j := 0; j < len(node.Content); j += 2 {
@@ -40,16 +50,20 @@ func yamlMapGet(key string) Getter {
 					return nil, fmt.Errorf("converting %q to int: %w", key, err)
 				}
 
+				if idx < 0 || idx > len(node.Content)-1 {
+					return nil, fmt.Errorf("index out of range: index = %v, len = %v", idx, len(node.Content))
+				}
+
 				found = node.Content[idx]
 			default:
-				return nil, fmt.Errorf("expected mapping or sequence node: got %+v(%v)", node, node.Kind)
+				return nil, fmt.Errorf("expected mapping or sequence node: got %v(%v)", node.Value, node.Kind)
 			}
 
 			if found == nil {
-				return nil, fmt.Errorf("%s does not have child named %s", node.Value, key)
+				return nil, fmt.Errorf("mapping at line %d does not have child named %s", node.Line, key)
 			}
 
-			return found, nil
+			return []*yaml.Node{found}, nil
 		},
 	}
 }
@@ -57,209 +71,638 @@ func yamlMapGet(key string) Getter {
 func yamlArrayElemIndex(idx int) Getter {
 	return Getter{
 		Expr: fmt.Sprintf("[%d]", idx),
-		Get: func(node *yaml.Node) (*yaml.Node, error) {
+		Get: func(node *yaml.Node) ([]*yaml.Node, error) {
+			node = resolveAlias(node)
+
 			if node.Kind != yaml.SequenceNode {
 				return nil, fmt.Errorf("expected sequence node: got %v(%v)", node.Value, node.Kind)
 			}
 
-			if idx > len(node.Content)-1 {
-				return nil, fmt.Errorf("index out of range: index = %v, len = %v, value = %+v", idx, len(node.Content), node)
+			// Negative indexes count from the end of the array
+			i := idx
+			if i < 0 {
+				i += len(node.Content)
+			}
+
+			if i < 0 || i > len(node.Content)-1 {
+				return nil, fmt.Errorf("index out of range: index = %v, len = %v", idx, len(node.Content))
 			}
 
-			return node.Content[idx], nil
+			return []*yaml.Node{node.Content[i]}, nil
 		},
 	}
 }
 
-func yamlArrayElemWhere(expr BoolExpr) Getter {
+// yamlArrayElemSlice selects array elements in the range as defined in RFC 9535. Omitted start and end are nil
+func yamlArrayElemSlice(start, end *int, step int, expr string) Getter {
 	return Getter{
-		Expr: expr.String(),
-		Get: func(node *yaml.Node) (*yaml.Node, error) {
+		Expr: expr,
+		Get: func(node *yaml.Node) ([]*yaml.Node, error) {
+			node = resolveAlias(node)
+
 			if node.Kind != yaml.SequenceNode {
 				return nil, fmt.Errorf("expected sequence node: got %v(%v)", node.Value, node.Kind)
 			}
 
-			for i, n := range node.Content {
+			l := len(node.Content)
+
+			normalize := func(i int) int {
+				if i < 0 {
+					return l + i
+				}
+
+				return i
+			}
+
+			clamp := func(i, min, max int) int {
+				if i < min {
+					return min
+				}
+
+				if i > max {
+					return max
+				}
+
+				return i
+			}
+
+			var found []*yaml.Node
+
+			switch {
+			case step > 0:
+				lower, upper := 0, l
+
+				if start != nil {
+					lower = clamp(normalize(*start), 0, l)
+				}
+
+				if end != nil {
+					upper = clamp(normalize(*end), 0, l)
+				}
+
+				for i := lower; i < upper; i += step {
+					found = append(found, node.Content[i])
+				}
+			case step < 0:
+				upper, lower := l-1, -1
+
+				if start != nil {
+					upper = clamp(normalize(*start), -1, l-1)
+				}
+
+				if end != nil {
+					lower = clamp(normalize(*end), -1, l-1)
+				}
+
+				for i := upper; lower < i; i += step {
+					found = append(found, node.Content[i])
+				}
+			}
+
+			return found, nil
+		},
+	}
+}
+
+// yamlWildcard selects all the values in the mapping or all the elements in the sequence
+func yamlWildcard() Getter {
+	return Getter{
+		Expr: "[*]",
+		Get: func(node *yaml.Node) ([]*yaml.Node, error) {
+			return children(resolveAlias(node)), nil
+		},
+	}
+}
+
+func yamlArrayElemWhere(expr BoolExpr) Getter {
+	return Getter{
+		Expr: fmt.Sprintf("[?(%s)]", expr.String()),
+		Get: func(node *yaml.Node) ([]*yaml.Node, error) {
+			node = resolveAlias(node)
+
+			if node.Kind != yaml.SequenceNode && node.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("expected sequence node: got %v(%v)", node.Value, node.Kind)
+			}
+
+			var found []*yaml.Node
+
+			for i, n := range children(node) {
 				ok, err := expr.Eval(Context{Current: n})
 				if err != nil {
 					return nil, fmt.Errorf("reading index %d in array: %w", i, err)
 				}
 
 				if ok {
-					return n, nil
+					found = append(found, n)
+				}
+			}
+
+			if len(found) == 0 {
+				return nil, fmt.Errorf("no array element matching `%s` found in %+v", expr.String(), node)
+			}
+
+			return found, nil
+		},
+	}
+}
+
+// yamlUnion selects nodes selected by any of the getters, like `[0,2]`
+func yamlUnion(getters []Getter, expr string) Getter {
+	return Getter{
+		Expr: expr,
+		Get: func(node *yaml.Node) ([]*yaml.Node, error) {
+			var (
+				found   []*yaml.Node
+				lastErr error
+			)
+
+			for _, g := range getters {
+				ns, err := g.Get(node)
+				if err != nil {
+					lastErr = err
+
+					continue
 				}
+
+				found = append(found, ns...)
+			}
+
+			if len(found) == 0 && lastErr != nil {
+				return nil, lastErr
+			}
+
+			return found, nil
+		},
+	}
+}
+
+// yamlDescendant applies the getter to the node and all of its descendants, like `..image`
+func yamlDescendant(g Getter) Getter {
+	expr := ".." + g.Expr
+	if strings.HasPrefix(g.Expr, ".") {
+		expr = "." + g.Expr
+	}
+
+	return Getter{
+		Expr: expr,
+		Get: func(node *yaml.Node) ([]*yaml.Node, error) {
+			var found []*yaml.Node
+
+			var walk func(n *yaml.Node)
+
+			walk = func(n *yaml.Node) {
+				n = resolveAlias(n)
+
+				// Nodes not selected from the descendant are just skipped
+				if ns, err := g.Get(n); err == nil {
+					found = append(found, ns...)
+				}
+
+				for _, c := range children(n) {
+					walk(c)
+				}
+			}
+
+			walk(node)
+
+			if len(found) == 0 {
+				return nil, fmt.Errorf("no descendant matching `%s` found", g.Expr)
 			}
 
-			return nil, fmt.Errorf("no array element matching `%s` found in %+v", expr.String(), node)
+			return found, nil
 		},
 	}
 }
 
-func (p *Path) Get(node *yaml.Node) (*yaml.Node, error) {
+// children returns values in the mapping, elements in the sequence, or nothing for the scalar
+func children(node *yaml.Node) []*yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		var values []*yaml.Node
+
+		for i := 1; i < len(node.Content); i += 2 {
+			values = append(values, node.Content[i])
+		}
+
+		return values
+	case yaml.SequenceNode:
+		return node.Content
+	default:
+		return nil
+	}
+}
+
+// resolveAlias returns the anchored node when the node is an alias like `*foo`
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	return node
+}
+
+// Get returns all the nodes matching the path in the document order.
+// It returns an error when nothing matched.
+func (p *Path) Get(node *yaml.Node) ([]*yaml.Node, error) {
+	nodes, _, err := p.walk(node)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// Nearest is like Get but returns the nodes matching the longest leading part of the path that matched anything,
+// along with the number of segments in the part.
+// The nodes are the nearest existing ancestors of the nodes at the path, like `spec` for `spec.securityContext` when `securityContext` is missing.
+func (p *Path) Nearest(node *yaml.Node) ([]*yaml.Node, int) {
+	nodes, depth, _ := p.walk(node)
+
+	return nodes, depth
+}
+
+// walk evaluates the segments of the path in order until nothing is selected.
+// It returns the nodes selected by the last successful segment and the number of the successful segments,
+// and an error describing why the next segment selected nothing.
+func (p *Path) walk(node *yaml.Node) ([]*yaml.Node, int, error) {
+	nodes := []*yaml.Node{node}
+
 	for i, g := range p.Getter {
-		next, err := g.Get(node)
-		if err != nil {
-			return nil, fmt.Errorf("evaluating jsonpath `%s` at %d: %w", g.Expr, i, err)
+		var (
+			next    []*yaml.Node
+			lastErr error
+		)
+
+		for _, n := range nodes {
+			ns, err := g.Get(n)
+			if err != nil {
+				lastErr = err
+
+				continue
+			}
+
+			next = append(next, ns...)
+		}
+
+		if len(next) == 0 {
+			if lastErr == nil {
+				lastErr = fmt.Errorf("no value selected")
+			}
+
+			return nodes, i, fmt.Errorf("evaluating jsonpath `%s` at %d: %w", g.Expr, i, lastErr)
 		}
 
-		node = next
+		nodes = next
 	}
 
-	return node, nil
+	return nodes, len(p.Getter), nil
+}
+
+// parseJSONPointer converts a JSON pointer like `/spec/containers/0/image` into the path to the same node
+func parseJSONPointer(ptr string) (*Path, error) {
+	var path Path
+
+	if ptr == "" {
+		return &path, nil
+	}
+
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("json pointer must start with /, but got: %s", ptr)
+	}
+
+	unescaper := strings.NewReplacer("~1", "/", "~0", "~")
+
+	for _, token := range strings.Split(ptr[1:], "/") {
+		path.Getter = append(path.Getter, yamlMapGet(unescaper.Replace(token)))
+	}
+
+	return &path, nil
 }
 
 func parseJsonpath(expr string) (*Path, error) {
-	type State int
-	const (
-		Init State = iota
-		Next
-		ReadPropertySelector
-		ReadArrayElementWithWhereClause
-		ReadArrayElementWithInlineWhereClause
-		ReadArrayElementWithIndex
-		End
-	)
+	if expr == "" {
+		return nil, fmt.Errorf("expression must start with $ or @, but got an empty string")
+	}
 
-	state := Init
+	if expr[0] != '$' && expr[0] != '@' {
+		return nil, fmt.Errorf("expression must start with $ or @, but got: %c in %s", expr[0], expr)
+	}
 
 	var path Path
 
-	for i := 0; i < len(expr); {
-		cur := expr[i]
+	for i := 1; i < len(expr); {
+		descendant := strings.HasPrefix(expr[i:], "..")
+
+		var (
+			getter Getter
+			err    error
+		)
+
+		switch {
+		case descendant && i+2 < len(expr) && expr[i+2] == '[':
+			getter, i, err = parseBracketSelectors(expr, i+2)
+		case descendant:
+			getter, i, err = parsePropertySelector(expr, i+2)
+		case expr[i] == '.':
+			getter, i, err = parsePropertySelector(expr, i+1)
+		case expr[i] == '[':
+			getter, i, err = parseBracketSelectors(expr, i)
+		default:
+			return nil, fmt.Errorf("reading next token from %s: unexpected string in buffer. expected any of %v", expr[i:], strings.Join([]string{".", "..", "["}, ", "))
+		}
 
-		switch state {
-		case Init:
-			if cur != '$' && cur != '@' {
-				return nil, fmt.Errorf("expression must start with $ or @, but got: %c in %s", cur, expr)
-			}
+		if err != nil {
+			return nil, err
+		}
 
-			if expr[i+1] != '.' {
-				return nil, fmt.Errorf("the root element must be an object value to be queried by .KEY, but got %s in %s", expr[i:i+2], expr)
-			}
+		if descendant {
+			getter = yamlDescendant(getter)
+		}
 
-			state = ReadPropertySelector
-			i += 1
-		case Next:
-			if expr[i] == '.' {
-				state = ReadPropertySelector
-			} else if strings.HasPrefix(expr[i:], "[*]?(@") {
-				state = ReadArrayElementWithWhereClause
-			} else if strings.HasPrefix(expr[i:], "[?(@") {
-				state = ReadArrayElementWithInlineWhereClause
-			} else if strings.HasPrefix(expr[i:], "[") {
-				state = ReadArrayElementWithIndex
-			} else {
-				return nil, fmt.Errorf("reading next token from %s: unexpected string in buffer. expected any of %v", expr[i:], strings.Join([]string{".", "["}, ", "))
-			}
-		case ReadPropertySelector:
-			start := i + 1
-			j := start
-
-			for {
-				if j == len(expr)-1 {
-					state = End
-					break
-				} else if expr[j+1] == '.' || expr[j+1] == '[' {
-					state = Next
-					break
-				} else if expr[j+1] == '-' || expr[j+1] == '+' {
-					return nil, fmt.Errorf("reading property selector from %s: unexpected character found at beginning", expr[j:])
-				}
+		path.Getter = append(path.Getter, getter)
+	}
 
-				j++
-			}
+	return &path, nil
+}
+
+// parsePropertySelector parses the property name or the wildcard starting at start,
+// and returns the getter and the index right after the selector
+func parsePropertySelector(expr string, start int) (Getter, int, error) {
+	j := start
 
-			getter := yamlMapGet(expr[start : j+1])
+	for j < len(expr) && expr[j] != '.' && expr[j] != '[' {
+		j++
+	}
 
-			path.Getter = append(path.Getter, getter)
+	name := expr[start:j]
 
-			i = j + 1
-		case ReadArrayElementWithIndex:
-			j := i
-			idxStart := i + 1
+	switch name {
+	case "":
+		return Getter{}, 0, fmt.Errorf("reading property selector from %s: missing property name", expr[start-1:])
+	case "*":
+		return yamlWildcard(), j, nil
+	}
 
-			for {
-				if j == len(expr)-1 {
-					return nil, fmt.Errorf("reading array element with index: unexpected EOS found at index %d", j)
-				} else if expr[j+1] == ']' {
-					break
-				}
-				j++
-			}
+	return yamlMapGet(name), j, nil
+}
 
-			idxStr := expr[idxStart : j+1]
-			idx, err := strconv.Atoi(idxStr)
-			if err != nil {
-				return nil, fmt.Errorf("converting %s to int: %w", idxStr, err)
-			}
+// parseBracketSelectors parses the comma-separated selectors within the brackets starting at start,
+// and returns the getter and the index right after the closing bracket
+func parseBracketSelectors(expr string, start int) (Getter, int, error) {
+	end := closingIndex(expr, start)
+	if end < 0 {
+		return Getter{}, 0, fmt.Errorf("reading bracket selector from %s: unexpected EOS", expr[start:])
+	}
 
-			getter := yamlArrayElemIndex(idx)
+	next := end + 1
 
-			path.Getter = append(path.Getter, getter)
+	// `[*]?(@...)` is the same as `[?(@...)]`
+	if expr[start+1:end] == "*" && strings.HasPrefix(expr[next:], "?(") {
+		condEnd := closingIndex(expr, next+1)
+		if condEnd < 0 {
+			return Getter{}, 0, fmt.Errorf("reading array element with where clause: unexpected EOS in %s", expr[next:])
+		}
 
-			state = Next
+		getter, err := parseFilterSelector(expr[next : condEnd+1])
 
-			i = j + 2
-		case ReadArrayElementWithWhereClause:
-			condStart := i + 5
-			j := condStart
+		return getter, condEnd + 1, err
+	}
 
-			for {
-				if j == len(expr)-1 {
-					return nil, fmt.Errorf("reading array element with where clause: unexpected EOS found at index %d", j)
-				} else if expr[j+1] == ')' {
-					break
-				}
-				j++
-			}
+	var getters []Getter
+
+	for _, sel := range splitTopLevel(expr[start+1:end], ',') {
+		getter, err := parseSelector(strings.TrimSpace(sel))
+		if err != nil {
+			return Getter{}, 0, fmt.Errorf("reading bracket selector from %s: %w", expr[start:next], err)
+		}
+
+		getters = append(getters, getter)
+	}
+
+	if len(getters) == 1 {
+		return getters[0], next, nil
+	}
+
+	return yamlUnion(getters, expr[start:next]), next, nil
+}
+
+// parseSelector parses a selector within brackets, like `*`, `-1`, `1:3`, `'app.kubernetes.io/name'` and `?(@.name == 'nginx')`
+func parseSelector(sel string) (Getter, error) {
+	switch {
+	case sel == "":
+		return Getter{}, fmt.Errorf("empty selector")
+	case sel == "*":
+		return yamlWildcard(), nil
+	case sel[0] == '?':
+		return parseFilterSelector(sel)
+	case sel[0] == '\'' || sel[0] == '"':
+		key, err := unquote(sel)
+		if err != nil {
+			return Getter{}, err
+		}
+
+		return yamlMapGet(key), nil
+	case strings.Contains(sel, ":"):
+		return parseSliceSelector(sel)
+	}
+
+	idx, err := strconv.Atoi(sel)
+	if err != nil {
+		return Getter{}, fmt.Errorf("converting %s to int: %w", sel, err)
+	}
+
+	return yamlArrayElemIndex(idx), nil
+}
+
+// parseFilterSelector parses the filter selector like `?(@.name == 'nginx')` and `?@.name == 'nginx'`
+func parseFilterSelector(sel string) (Getter, error) {
+	cond := strings.TrimSpace(sel[1:])
+
+	if strings.HasPrefix(cond, "(") && closingIndex(cond, 0) == len(cond)-1 {
+		cond = cond[1 : len(cond)-1]
+	}
+
+	boolExp, err := parseBoolExp(cond)
+	if err != nil {
+		return Getter{}, fmt.Errorf("parsing %s: %w", cond, err)
+	}
+
+	return yamlArrayElemWhere(boolExp), nil
+}
+
+// parseSliceSelector parses the slice selector like `1:3`, `:-1` and `::-1`
+func parseSliceSelector(sel string) (Getter, error) {
+	parts := strings.Split(sel, ":")
+	if len(parts) > 3 {
+		return Getter{}, fmt.Errorf("too many colons in slice %s", sel)
+	}
+
+	var bounds [2]*int
+
+	for i := 0; i < 2; i++ {
+		p := strings.TrimSpace(parts[i])
+		if p == "" {
+			continue
+		}
+
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return Getter{}, fmt.Errorf("converting %s to int: %w", p, err)
+		}
+
+		bounds[i] = &v
+	}
+
+	step := 1
+
+	if len(parts) == 3 && strings.TrimSpace(parts[2]) != "" {
+		v, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		if err != nil {
+			return Getter{}, fmt.Errorf("converting %s to int: %w", parts[2], err)
+		}
+
+		step = v
+	}
+
+	return yamlArrayElemSlice(bounds[0], bounds[1], step, "["+sel+"]"), nil
+}
+
+// unquote returns the string within the single or double quotes, like `'app.kubernetes.io/name'`, with escape sequences replaced
+func unquote(s string) (string, error) {
+	quote := s[0]
+
+	if len(s) < 2 || s[len(s)-1] != quote {
+		return "", fmt.Errorf("unterminated string %s", s)
+	}
 
-			condStr := expr[condStart : j+1]
+	var b strings.Builder
 
-			boolExp, err := parseBoolExp(condStr)
+	for i := 1; i < len(s)-1; i++ {
+		c := s[i]
+
+		switch {
+		case c == quote:
+			return "", fmt.Errorf("unexpected %c in string %s", quote, s)
+		case c != '\\':
+			b.WriteByte(c)
+
+			continue
+		}
+
+		i++
+
+		if i == len(s)-1 {
+			return "", fmt.Errorf("unterminated escape sequence in string %s", s)
+		}
+
+		switch s[i] {
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if i+4 >= len(s)-1 {
+				return "", fmt.Errorf("invalid unicode escape sequence in string %s", s)
+			}
+
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
 			if err != nil {
-				return nil, fmt.Errorf("parsing %s: %w", condStr, err)
+				return "", fmt.Errorf("invalid unicode escape sequence in string %s: %w", s, err)
 			}
 
-			getter := yamlArrayElemWhere(boolExp)
+			b.WriteRune(rune(r))
 
-			path.Getter = append(path.Getter, getter)
+			i += 4
+		default:
+			// \', \", \/ and \\ stand for themselves
+			b.WriteByte(s[i])
+		}
+	}
 
-			state = Next
+	return b.String(), nil
+}
 
-			i = j + 2
-		case ReadArrayElementWithInlineWhereClause:
-			condStart := i + 3
-			j := condStart
+// closingIndex returns the index of the bracket or the parenthesis closing the one at start, or -1 if not closed.
+// Brackets and parentheses within quoted strings are ignored.
+func closingIndex(expr string, start int) int {
+	depth := 0
 
-			for {
-				if j == len(expr)-1 {
-					return nil, fmt.Errorf("reading array element with inline where clause: unexpected EOS found at index %d", j)
-				} else if expr[j+1] == ')' {
-					break
-				}
-				j++
+	var quote byte
+
+	for i := start; i < len(expr); i++ {
+		c := expr[i]
+
+		if quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case quote:
+				quote = 0
 			}
 
-			condStr := expr[condStart : j+1]
+			continue
+		}
+
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
 
-			boolExp, err := parseBoolExp(condStr)
-			if err != nil {
-				return nil, fmt.Errorf("parsing %s: %w", condStr, err)
+			if depth == 0 {
+				return i
 			}
+		}
+	}
+
+	return -1
+}
 
-			getter := yamlArrayElemWhere(boolExp)
+// splitTopLevel splits s by sep outside of quoted strings, brackets and parentheses
+func splitTopLevel(s string, sep byte) []string {
+	var (
+		parts []string
+		depth int
+		quote byte
+		last  int
+	)
 
-			path.Getter = append(path.Getter, getter)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
 
-			state = Next
+		if quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case quote:
+				quote = 0
+			}
 
-			i = j + 3
-		case End:
+			continue
+		}
 
-		default:
-			panic(fmt.Errorf("unexpected state: %v", state))
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[last:i])
+				last = i + 1
+			}
 		}
 	}
 
-	return &path, nil
+	return append(parts, s[last:])
 }
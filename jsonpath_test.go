@@ -86,6 +86,113 @@ func TestJsonpath(t *testing.T) {
 			col:  17,
 			val:  "true",
 		},
+		{
+			expr: `$..image`,
+			data: `spec:
+  containers:
+  - name: fluentd
+    image: fluentd:v1
+  - name: nginx
+    image: nginx:1.17.3
+`,
+			line: 4,
+			col:  12,
+			val:  "fluentd:v1",
+		},
+		{
+			expr: `$.spec.containers[*].image`,
+			data: `spec:
+  containers:
+  - name: fluentd
+  - name: nginx
+    image: nginx:1.17.3
+`,
+			line: 5,
+			col:  12,
+			val:  "nginx:1.17.3",
+		},
+		{
+			expr: `$.spec.containers[-1].name`,
+			data: `spec:
+  containers:
+  - name: fluentd
+  - name: nginx
+`,
+			line: 4,
+			col:  11,
+			val:  "nginx",
+		},
+		{
+			expr: `$.spec.containers[::-1].name`,
+			data: `spec:
+  containers:
+  - name: fluentd
+  - name: nginx
+`,
+			line: 4,
+			col:  11,
+			val:  "nginx",
+		},
+		{
+			expr: `$.spec.containers[1:].name`,
+			data: `spec:
+  containers:
+  - name: fluentd
+  - name: nginx
+`,
+			line: 4,
+			col:  11,
+			val:  "nginx",
+		},
+		{
+			expr: `$.spec.*.replicas`,
+			data: `spec:
+  template:
+    replicas: 1
+`,
+			line: 3,
+			col:  15,
+			val:  "1",
+		},
+		{
+			expr: `$.metadata.annotations['app.kubernetes.io/name']`,
+			data: `metadata:
+  annotations:
+    app.kubernetes.io/name: hello
+`,
+			line: 3,
+			col:  29,
+			val:  "hello",
+		},
+		{
+			expr: `$.metadata.labels["my label-1"].foo`,
+			data: `metadata:
+  labels:
+    my label-1:
+      foo: bar
+`,
+			line: 4,
+			col:  12,
+			val:  "bar",
+		},
+		{
+			expr: `$['metadata']['it\'s', "a,b[0]"]`,
+			data: `metadata:
+  it's: 1
+  a,b[0]: 2
+`,
+			line: 2,
+			col:  9,
+			val:  "1",
+		},
+		{
+			expr: `$.spec.containers[5]`,
+			data: `spec:
+  containers:
+  - name: fluentd
+`,
+			jsonpathGetErr: "evaluating jsonpath `[5]` at 2: index out of range: index = 5, len = 1",
+		},
 		{
 			expr: `$.spec.containers`,
 			data: `spec:
@@ -125,17 +232,21 @@ func TestJsonpath(t *testing.T) {
 
 			mappingNode := root.Content[0]
 
-			got, err := path.Get(mappingNode)
+			nodes, err := path.Get(mappingNode)
 			if err != nil {
 				if tc.jsonpathGetErr == "" {
-					t.Fatalf("unexpected error: %w", err)
+					t.Fatalf("unexpected error: %v", err)
 				} else if err.Error() != tc.jsonpathGetErr {
 					t.Fatalf("unexpected error: want %q, got %q", tc.jsonpathGetErr, err.Error())
 				}
+
+				return
 			} else if tc.jsonpathGetErr != "" {
-				t.Fatalf("expected error: want %w, got none", tc.jsonpathGetErr)
+				t.Fatalf("expected error: want %v, got none", tc.jsonpathGetErr)
 			}
 
+			got := nodes[0]
+
 			if got.Value != tc.val {
 				t.Errorf("unexpected result: want %v, got %v", tc.val, got.Value)
 			}
@@ -150,3 +261,155 @@ func TestJsonpath(t *testing.T) {
 		})
 	}
 }
+
+func TestJsonpathMultipleMatches(t *testing.T) {
+	data := `spec:
+  containers:
+  - name: fluentd
+    image: fluentd:v1
+  - name: nginx
+    image: nginx:1.17.3
+  initContainers:
+  - name: init
+    image: busybox
+`
+
+	testcases := []struct {
+		expr  string
+		lines []int
+	}{
+		{expr: `$..image`, lines: []int{4, 6, 9}},
+		{expr: `$.spec.containers[*].name`, lines: []int{3, 5}},
+		{expr: `$.spec.containers[1,0].name`, lines: []int{5, 3}},
+		{expr: `$.spec..[?(@.name == 'nginx')].image`, lines: []int{6}},
+		{expr: `$.spec.containers[0:1].name`, lines: []int{3}},
+		{expr: `$..[?(@.image =~ /^(nginx|busybox)/ && (@.name != 'init' || !@.image))].name`, lines: []int{5}},
+	}
+
+	for i := range testcases {
+		tc := testcases[i]
+
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			path, err := parseJsonpath(tc.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			root := yaml.Node{}
+
+			if err := yaml.Unmarshal([]byte(data), &root); err != nil {
+				t.Fatal("bug: failed parsing yaml")
+			}
+
+			nodes, err := path.Get(root.Content[0])
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var lines []int
+
+			for _, n := range nodes {
+				lines = append(lines, n.Line)
+			}
+
+			if fmt.Sprint(lines) != fmt.Sprint(tc.lines) {
+				t.Errorf("unexpected lines for %s: want %v, got %v", tc.expr, tc.lines, lines)
+			}
+		})
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	data := `metadata:
+  annotations:
+    example.com/name: hello
+spec:
+  containers:
+  - name: fluentd
+  - name: nginx
+`
+
+	testcases := []struct {
+		ptr       string
+		line, col int
+	}{
+		{ptr: "", line: 1, col: 1},
+		{ptr: "/spec/containers/1/name", line: 7, col: 11},
+		{ptr: "/metadata/annotations/example.com~1name", line: 3, col: 23},
+	}
+
+	for i := range testcases {
+		tc := testcases[i]
+
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			path, err := parseJSONPointer(tc.ptr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			root := yaml.Node{}
+
+			if err := yaml.Unmarshal([]byte(data), &root); err != nil {
+				t.Fatal("bug: failed parsing yaml")
+			}
+
+			nodes, err := path.Get(root.Content[0])
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := nodes[0]
+
+			if got.Line != tc.line || got.Column != tc.col {
+				t.Errorf("unexpected position: want %d:%d, got %d:%d", tc.line, tc.col, got.Line, got.Column)
+			}
+		})
+	}
+}
+
+func TestJsonpathNearest(t *testing.T) {
+	data := `spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+`
+
+	testcases := []struct {
+		expr  string
+		depth int
+		line  int
+	}{
+		{expr: `$.spec.template.spec.securityContext.runAsNonRoot`, depth: 3, line: 4},
+		{expr: `$.spec.template.spec.containers[?(@.name == 'fluentd')]`, depth: 4, line: 5},
+		{expr: `$.metadata`, depth: 0, line: 1},
+		{expr: `$.spec.template`, depth: 2, line: 3},
+	}
+
+	for i := range testcases {
+		tc := testcases[i]
+
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			path, err := parseJsonpath(tc.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			root := yaml.Node{}
+
+			if err := yaml.Unmarshal([]byte(data), &root); err != nil {
+				t.Fatal("bug: failed parsing yaml")
+			}
+
+			nodes, depth := path.Nearest(root.Content[0])
+
+			if depth != tc.depth {
+				t.Errorf("unexpected depth: want %d, got %d", tc.depth, depth)
+			}
+
+			if len(nodes) != 1 || nodes[0].Line != tc.line {
+				t.Errorf("unexpected nodes: want a node at line %d, got %v", tc.line, nodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package conflint
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// JUnitReporter prints a JUnit XML report, in which every config entry is a test suite
+// and every file checked by the entry is a test case.
+// Every error becomes a failure of the test case for the file.
+// Warnings become failures only when the config entry fails on warnings. Otherwise they are written to the system-out.
+type JUnitReporter struct {
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	TestCases []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+	SystemOut string         `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (j *JUnitReporter) Report(w io.Writer, results []Result) error {
+	out := junitTestSuites{}
+
+	for _, res := range results {
+		suite := &junitTestSuite{Name: res.Name}
+
+		testCases := map[string]*junitTestCase{}
+
+		testCase := func(file string) *junitTestCase {
+			tc, ok := testCases[file]
+			if !ok {
+				tc = &junitTestCase{Name: file, ClassName: res.Name}
+				testCases[file] = tc
+				suite.TestCases = append(suite.TestCases, tc)
+			}
+
+			return tc
+		}
+
+		for _, f := range res.Files {
+			testCase(f)
+		}
+
+		for _, d := range res.Diagnostics {
+			tc := testCase(d.File)
+
+			text := fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message)
+
+			if d.Severity == SeverityWarning && !res.FailOnWarn && !d.FailOnWarn {
+				tc.SystemOut += text + "\n"
+
+				continue
+			}
+
+			tc.Failures = append(tc.Failures, junitFailure{
+				Message: d.Message,
+				Type:    d.Rule,
+				Text:    text,
+			})
+		}
+
+		for _, tc := range suite.TestCases {
+			if len(tc.Failures) > 0 {
+				suite.Failures++
+			}
+		}
+
+		suite.Tests = len(suite.TestCases)
+
+		out.Tests += suite.Tests
+		out.Failures += suite.Failures
+		out.Suites = append(out.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("encoding junit xml: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
@@ -0,0 +1,69 @@
+package conflint
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJUnitReporter(t *testing.T) {
+	results := []Result{
+		{
+			Linter: "conftest",
+			Name:   "conftest[0]",
+			Files:  []string{"app1/nginx.deploy.yaml", "app1/nginx.svc.yaml"},
+			Diagnostics: []Diagnostic{
+				{
+					Linter:   "conftest",
+					Rule:     "deny",
+					Severity: SeverityError,
+					File:     "app1/nginx.deploy.yaml",
+					Line:     15,
+					Column:   11,
+					Message:  "`privileged: true` is forbidden",
+				},
+				{
+					Linter:   "conftest",
+					Rule:     "warn",
+					Severity: SeverityWarning,
+					File:     "app1/nginx.svc.yaml",
+					Line:     1,
+					Column:   13,
+					Message:  "Too old apiVersion",
+				},
+			},
+		},
+		{
+			Linter: "kubeval",
+			Name:   "kubeval[0]",
+			Files:  []string{"app1/nginx.deploy.yaml"},
+		},
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1">
+  <testsuite name="conftest[0]" tests="2" failures="1">
+    <testcase name="app1/nginx.deploy.yaml" classname="conftest[0]">
+      <failure message="` + "`privileged: true` is forbidden" + `" type="deny">app1/nginx.deploy.yaml:15:11: ` + "`privileged: true` is forbidden" + `</failure>
+    </testcase>
+    <testcase name="app1/nginx.svc.yaml" classname="conftest[0]">
+      <system-out>app1/nginx.svc.yaml:1:13: Too old apiVersion&#xA;</system-out>
+    </testcase>
+  </testsuite>
+  <testsuite name="kubeval[0]" tests="1" failures="0">
+    <testcase name="app1/nginx.deploy.yaml" classname="kubeval[0]"></testcase>
+  </testsuite>
+</testsuites>
+`
+
+	buf := &bytes.Buffer{}
+
+	if err := (&JUnitReporter{}).Report(buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("unexpected output: %s", diff)
+	}
+}
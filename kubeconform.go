@@ -0,0 +1,152 @@
+package conflint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+type KubeconformConfig struct {
+	EntryConfig `yaml:",inline"`
+
+	Files []string `yaml:"files"`
+	// SchemaLocations is passed to kubeconform via `-schema-location`, in the order of precedence.
+	// Use `default` to include the kubeconform's default location along with yours
+	SchemaLocations      []string `yaml:"schemaLocations"`
+	SkipKinds            []string `yaml:"skipKinds"`
+	RejectKinds          []string `yaml:"rejectKinds"`
+	Strict               bool     `yaml:"strict"`
+	KubernetesVersion    string   `yaml:"kubernetesVersion"`
+	IgnoreMissingSchemas bool     `yaml:"ignoreMissingSchemas"`
+}
+
+// KubeconformOutput is the output of `kubeconform -output json`
+type KubeconformOutput struct {
+	Resources []KubeconformResource `json:"resources"`
+}
+
+type KubeconformResource struct {
+	Filename         string                       `json:"filename"`
+	Kind             string                       `json:"kind"`
+	Name             string                       `json:"name"`
+	Version          string                       `json:"version"`
+	Status           string                       `json:"status"`
+	Msg              string                       `json:"msg"`
+	ValidationErrors []KubeconformValidationError `json:"validationErrors"`
+}
+
+type KubeconformValidationError struct {
+	// Path is the JSON pointer to the invalid value within the document
+	Path string `json:"path"`
+	Msg  string `json:"msg"`
+}
+
+// KubeconformLinter runs kubeconform once per file pattern in Files
+type KubeconformLinter struct {
+	KubeconformConfig
+}
+
+func (k *KubeconformLinter) DecodeConfig(node *yaml.Node) error {
+	return node.Decode(&k.KubeconformConfig)
+}
+
+func (k *KubeconformLinter) Expand(r *Runner) ([][]string, error) {
+	return globGroups(r.WorkDir, k.Files, false)
+}
+
+func (k *KubeconformLinter) Exec(ctx context.Context, r *Runner, files []string) ([]byte, error) {
+	args := []string{"-output", "json"}
+	if k.Strict {
+		args = append(args, "-strict")
+	}
+	if k.IgnoreMissingSchemas {
+		args = append(args, "-ignore-missing-schemas")
+	}
+	if k.KubernetesVersion != "" {
+		args = append(args, "-kubernetes-version", k.KubernetesVersion)
+	}
+	if len(k.SkipKinds) > 0 {
+		args = append(args, "-skip", strings.Join(k.SkipKinds, ","))
+	}
+	if len(k.RejectKinds) > 0 {
+		args = append(args, "-reject", strings.Join(k.RejectKinds, ","))
+	}
+	for _, l := range k.SchemaLocations {
+		args = append(args, "-schema-location", l)
+	}
+	args = append(args, files...)
+
+	return execCommand(ctx, r, "kubeconform", args...)
+}
+
+func (k *KubeconformLinter) Diagnostics(r *Runner, files []string, out []byte) ([]Diagnostic, error) {
+	var kubeconformOut KubeconformOutput
+
+	if err := json.Unmarshal(out, &kubeconformOut); err != nil {
+		fmt.Fprintf(os.Stderr, "kubeconform failed with output:\n%s", string(out))
+
+		return nil, fmt.Errorf("unmarshalling json: %w", err)
+	}
+
+	var diags []Diagnostic
+
+	locator := newPointerLocator(r)
+
+	for _, res := range kubeconformOut.Resources {
+		var rule string
+
+		switch res.Status {
+		case "statusInvalid":
+			rule = "schema"
+		case "statusError":
+			rule = "error"
+		default:
+			continue
+		}
+
+		// kubeconform reports neither document indices nor namespaces, so the kind and the name are the best we can match on
+		sel := DocumentSelector{Kind: res.Kind, Name: res.Name}
+
+		locate := func(ptr, msg string) error {
+			d, err := locator.Locate(res.Filename, sel, ptr)
+			if err != nil {
+				return err
+			}
+
+			d.Linter = "kubeconform"
+			d.Rule = rule
+			d.Severity = SeverityError
+			d.Message = msg
+
+			diags = append(diags, d)
+
+			return nil
+		}
+
+		if len(res.ValidationErrors) == 0 {
+			// Errors like missing schemas are about the document as a whole
+			ptr := ""
+			if res.Kind != "" {
+				ptr = "/kind"
+			}
+
+			if err := locate(ptr, res.Msg); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		for _, e := range res.ValidationErrors {
+			if err := locate(e.Path, e.Msg); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return diags, nil
+}
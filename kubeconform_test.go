@@ -0,0 +1,100 @@
+package conflint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKubeconformDiagnostics(t *testing.T) {
+	out := `{
+  "resources": [
+    {
+      "filename": "app1/resources.yaml",
+      "kind": "Deployment",
+      "name": "hello",
+      "version": "apps/v1",
+      "status": "statusValid",
+      "msg": ""
+    },
+    {
+      "filename": "app1/resources.yaml",
+      "kind": "Deployment",
+      "name": "world",
+      "version": "apps/v1",
+      "status": "statusInvalid",
+      "msg": "problem validating schema",
+      "validationErrors": [
+        {
+          "path": "/spec/replicas",
+          "msg": "expected integer or null, but got string"
+        },
+        {
+          "path": "/spec/template/spec/containers/0",
+          "msg": "missing properties: 'ports'"
+        }
+      ]
+    },
+    {
+      "filename": "app1/resources.yaml",
+      "kind": "Widget",
+      "name": "hello",
+      "version": "example.com/v1",
+      "status": "statusError",
+      "msg": "could not find schema for Widget"
+    }
+  ]
+}`
+
+	runner := &Runner{WorkDir: filepath.Join("testdata", "kubeconform")}
+
+	k := &KubeconformLinter{}
+
+	diags, err := k.Diagnostics(runner, []string{"app1/resources.yaml"}, []byte(out))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Diagnostic{
+		{Linter: "kubeconform", Rule: "schema", Severity: SeverityError, File: "app1/resources.yaml", Line: 13, Column: 13, EndLine: 13, EndColumn: 16, Message: "expected integer or null, but got string"},
+		{Linter: "kubeconform", Rule: "schema", Severity: SeverityError, File: "app1/resources.yaml", Line: 17, Column: 11, EndLine: 18, EndColumn: 22, Message: "missing properties: 'ports'"},
+		{Linter: "kubeconform", Rule: "error", Severity: SeverityError, File: "app1/resources.yaml", Line: 21, Column: 7, EndLine: 21, EndColumn: 13, Message: "could not find schema for Widget"},
+	}
+
+	if d := cmp.Diff(want, diags); d != "" {
+		t.Errorf("unexpected diagnostics: want (-), got (+):\n%s", d)
+	}
+}
+
+func TestKubeconformDiagnosticsMalformedManifest(t *testing.T) {
+	out := `{
+  "resources": [
+    {
+      "filename": "app1/broken.yaml",
+      "kind": "",
+      "name": "",
+      "version": "",
+      "status": "statusError",
+      "msg": "error unmarshalling resource: error converting YAML to JSON: yaml: line 6: did not find expected node content"
+    }
+  ]
+}`
+
+	runner := &Runner{WorkDir: filepath.Join("testdata", "kubeconform")}
+
+	k := &KubeconformLinter{}
+
+	diags, err := k.Diagnostics(runner, []string{"app1/broken.yaml"}, []byte(out))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Diagnostic{
+		{Linter: "kubeconform", Rule: "error", Severity: SeverityError, File: "app1/broken.yaml", Line: 1, Column: 1, Message: "error unmarshalling resource: error converting YAML to JSON: yaml: line 6: did not find expected node content", Approximate: true},
+	}
+
+	if d := cmp.Diff(want, diags); d != "" {
+		t.Errorf("unexpected diagnostics: want (-), got (+):\n%s", d)
+	}
+}
@@ -0,0 +1,167 @@
+package conflint
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+type KubevalConfig struct {
+	EntryConfig `yaml:",inline"`
+
+	Files                   []string `yaml:"files"`
+	Strict                  bool     `yaml:"strict"`
+	SchemaLocations         []string `yaml:"schemaLocations"`
+	IgnoreMissingSchemas    bool     `yaml:"ignoreMissingSchemas"`
+	IgnoredFilenamePatterns []string `yaml:"ignoredFilenamePatterns"`
+	SkipKinds               []string `yaml:"skipKinds"`
+}
+
+type KubevalOutput = []KubevalFileResult
+
+type KubevalFileResult struct {
+	Filename string   `yaml:"filename"`
+	Kind     string   `yaml:"kind"`
+	Status   string   `yaml:"status"`
+	Errors   []string `yaml:"errors"`
+}
+
+// KubevalLinter runs kubeval against every file matching Files, one by one
+type KubevalLinter struct {
+	KubevalConfig
+}
+
+func (k *KubevalLinter) DecodeConfig(node *yaml.Node) error {
+	return node.Decode(&k.KubevalConfig)
+}
+
+func (k *KubevalLinter) Expand(r *Runner) ([][]string, error) {
+	return globGroups(r.WorkDir, k.Files, true)
+}
+
+func (k *KubevalLinter) Exec(ctx context.Context, r *Runner, files []string) ([]byte, error) {
+	args := append([]string{}, files...)
+	args = append(args, "-o", "json")
+	if k.Strict {
+		args = append(args, "--strict")
+	}
+	if k.IgnoreMissingSchemas {
+		args = append(args, "--ignore-missing-schemas")
+	}
+	if len(k.IgnoredFilenamePatterns) > 0 {
+		args = append(args, "--ignored-filename-patterns", strings.Join(k.IgnoredFilenamePatterns, ","))
+	}
+	if len(k.SkipKinds) > 0 {
+		args = append(args, "--skip-kinds", strings.Join(k.SkipKinds, ","))
+	}
+	if len(k.SchemaLocations) > 0 {
+		args = append(args, "--schema-location", k.SchemaLocations[0])
+
+		if len(k.SchemaLocations) > 1 {
+			args = append(args, "--additional-schema-locations", strings.Join(k.SchemaLocations[1:], ","))
+		}
+	}
+
+	return execCommand(ctx, r, "kubeval", args...)
+}
+
+func (k *KubevalLinter) Diagnostics(r *Runner, files []string, out []byte) ([]Diagnostic, error) {
+	var effectiveLines []string
+
+	allLines := bufio.NewScanner(bytes.NewReader(out))
+	for allLines.Scan() {
+		line := allLines.Text()
+
+		if strings.HasPrefix(line, "WARN - Set to ignore missing schemas") {
+
+		} else {
+			effectiveLines = append(effectiveLines, line)
+		}
+	}
+
+	var kubevalOut KubevalOutput
+
+	jsonDocText := []byte(strings.Join(effectiveLines, "\n"))
+	if err := yaml.Unmarshal(jsonDocText, &kubevalOut); err != nil {
+		fmt.Fprintf(os.Stderr, "kubeeval failed with output:\n%s", string(out))
+
+		return nil, fmt.Errorf("unmarshalling yaml: %w", err)
+	}
+
+	var diags []Diagnostic
+
+	// kubeval reports every document in the order of appearance, including empty ones having neither kinds nor errors.
+	// Counting the others gives the position of the reported document among the non-empty documents in the file
+	counts := map[string]int{}
+	docs := map[string][]int{}
+
+	for _, res := range kubevalOut {
+		sel := DocumentSelector{Kind: res.Kind}
+
+		if res.Kind != "" || len(res.Errors) > 0 {
+			if _, ok := docs[res.Filename]; !ok {
+				docs[res.Filename] = nonEmptyDocuments(filepath.Join(r.WorkDir, res.Filename))
+			}
+
+			if n := counts[res.Filename]; n < len(docs[res.Filename]) {
+				sel.Index = &docs[res.Filename][n]
+			}
+
+			counts[res.Filename]++
+		}
+
+		handle := func(msg string) error {
+			sub := strings.SplitN(msg, ": ", 2)
+			if len(sub) > 1 {
+				ds, err := r.LocateInDocument(res.Filename, sel, messageJsonpath(sub[0]))
+				if err != nil {
+					return fmt.Errorf("processing %s: %w", sub[0], err)
+				}
+				for _, d := range ds {
+					d.Linter = "kubeval"
+					d.Rule = "schema"
+					d.Severity = SeverityError
+					d.Message = sub[1]
+					diags = append(diags, d)
+				}
+			} else {
+				log.Printf("ignoring unsupported output: %s", msg)
+			}
+
+			return nil
+		}
+
+		for _, f := range res.Errors {
+			if err := handle(f); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return diags, nil
+}
+
+// nonEmptyDocuments returns the indices of the non-empty documents in the file, or nil when the file can't be read
+func nonEmptyDocuments(file string) []int {
+	files, err := ReadYAMLFiles(file)
+	if err != nil {
+		return nil
+	}
+
+	var indices []int
+
+	for i, doc := range files[file] {
+		if !isEmptyDocument(&doc) {
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
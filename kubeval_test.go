@@ -0,0 +1,36 @@
+package conflint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKubevalDiagnosticsMultipleDocuments(t *testing.T) {
+	// kubeval reports the empty document before the first `---` and the comment-only document as well
+	out := `[
+  {"filename": "app1/deployments.yaml", "kind": "", "status": "skipped", "errors": []},
+  {"filename": "app1/deployments.yaml", "kind": "Deployment", "status": "invalid", "errors": ["spec.template.spec.containers.0.image: Does not match pattern '^[a-z.]+:[0-9.]+$'"]},
+  {"filename": "app1/deployments.yaml", "kind": "", "status": "skipped", "errors": []},
+  {"filename": "app1/deployments.yaml", "kind": "Deployment", "status": "invalid", "errors": ["spec.template.spec.containers.0.image: Does not match pattern '^[a-z.]+:[0-9.]+$'"]}
+]`
+
+	k := &KubevalLinter{}
+
+	runner := &Runner{WorkDir: filepath.Join("testdata", "kubeval-multi")}
+
+	diags, err := k.Diagnostics(runner, []string{"app1/deployments.yaml"}, []byte(out))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Diagnostic{
+		{Linter: "kubeval", Rule: "schema", Severity: SeverityError, File: "app1/deployments.yaml", Line: 10, Column: 16, EndLine: 10, EndColumn: 28, Message: "Does not match pattern '^[a-z.]+:[0-9.]+$'"},
+		{Linter: "kubeval", Rule: "schema", Severity: SeverityError, File: "app1/deployments.yaml", Line: 23, Column: 16, EndLine: 23, EndColumn: 22, Message: "Does not match pattern '^[a-z.]+:[0-9.]+$'"},
+	}
+
+	if d := cmp.Diff(want, diags); d != "" {
+		t.Errorf("unexpected diagnostics: want (-), got (+):\n%s", d)
+	}
+}
@@ -0,0 +1,529 @@
+package conflint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	kustomizeOriginAnnotation          = "config.kubernetes.io/origin"
+	kustomizeTransformationsAnnotation = "alpha.config.kubernetes.io/transformations"
+)
+
+type KustomizeConfig struct {
+	EntryConfig `yaml:",inline"`
+
+	// Dir is the path to the directory containing kustomization.yaml, like an overlay
+	Dir string `yaml:"dir"`
+	// Linters is the config of the linters run against the built manifests, in the same format as the top-level config
+	Linters yaml.Node `yaml:"linters"`
+}
+
+// KustomizeLinter builds the kustomization with `kustomize build` and runs the nested linters against the built manifests.
+//
+// Diagnostics are mapped back to the files that produced the resources, according to the annotations added by kustomize
+// when `buildMetadata: [originAnnotations, transformerAnnotations]` is set in the kustomization.
+type KustomizeLinter struct {
+	KustomizeConfig
+}
+
+// kustomizeOrigin is the content of the origin annotation, or an item of the transformations annotation
+type kustomizeOrigin struct {
+	// Path is the path to the file the resource is read from, relative to the kustomization being built
+	Path string `yaml:"path"`
+	// ConfiguredIn is the path to the kustomization that configured the generator or the transformer
+	ConfiguredIn string `yaml:"configuredIn"`
+	ConfiguredBy struct {
+		Kind string `yaml:"kind"`
+	} `yaml:"configuredBy"`
+}
+
+// kustomization is the subset of kustomization.yaml needed to find patch files
+type kustomization struct {
+	Patches []struct {
+		Path string `yaml:"path"`
+	} `yaml:"patches"`
+	PatchesStrategicMerge []string `yaml:"patchesStrategicMerge"`
+	PatchesJSON6902       []struct {
+		Path string `yaml:"path"`
+	} `yaml:"patchesJson6902"`
+}
+
+func (k *KustomizeLinter) DecodeConfig(node *yaml.Node) error {
+	if err := node.Decode(&k.KustomizeConfig); err != nil {
+		return err
+	}
+
+	if k.Dir == "" {
+		return fmt.Errorf("line %d: dir is required", node.Line)
+	}
+
+	if k.Linters.Kind == 0 {
+		return fmt.Errorf("line %d: linters is required", node.Line)
+	}
+
+	// Catch errors in the nested config before building anything
+	config, err := nestedConfig(&k.Linters, "", nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := decodeConfigNode(config); err != nil {
+		return fmt.Errorf("decoding linters: %w", err)
+	}
+
+	return nil
+}
+
+func (k *KustomizeLinter) Expand(r *Runner) ([][]string, error) {
+	return [][]string{{k.Dir}}, nil
+}
+
+func (k *KustomizeLinter) Lint(ctx context.Context, r *Runner, files []string) ([]Diagnostic, error) {
+	out, err := renderCommand(ctx, r, "kustomize", "build", k.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every resource is written into its own file, so that each diagnostic can be mapped back according to the resource's annotations
+	rendered := map[string][]byte{}
+
+	for i, doc := range strings.Split("\n"+string(out), "\n---\n") {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+
+		rendered[fmt.Sprintf("%s/%03d.yaml", filepath.Base(k.Dir), i)] = []byte(strings.TrimPrefix(doc, "\n") + "\n")
+	}
+
+	diags, err := lintRendered(ctx, r, "kustomize", &k.Linters, rendered)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, d := range diags {
+		d, err := k.mapToSource(r, d, rendered[d.File])
+		if err != nil {
+			return nil, err
+		}
+
+		diags[i] = d
+	}
+
+	return diags, nil
+}
+
+// mapToSource moves the diagnostic located in the built resource to the file that produced the line.
+//
+// The diagnostic is moved to a patch applied to the resource when the patch sets the located node to the built value, as patches override the original resource.
+// Otherwise it's moved to the original resource, searched in the same way as helm templates.
+// The diagnostic is moved to the top of the kustomization when the resource has no origin annotation.
+func (k *KustomizeLinter) mapToSource(r *Runner, d Diagnostic, rendered []byte) (Diagnostic, error) {
+	var resource struct {
+		Kind     string `yaml:"kind"`
+		Metadata struct {
+			Name        string            `yaml:"name"`
+			Annotations map[string]string `yaml:"annotations"`
+		} `yaml:"metadata"`
+	}
+
+	if err := yaml.Unmarshal(rendered, &resource); err != nil {
+		return d, fmt.Errorf("decoding built resource: %w", err)
+	}
+
+	renderedLines := strings.Split(string(rendered), "\n")
+
+	d.EndLine, d.EndColumn = 0, 0
+
+	var transformations []kustomizeOrigin
+
+	if a := resource.Metadata.Annotations[kustomizeTransformationsAnnotation]; a != "" {
+		if err := yaml.Unmarshal([]byte(a), &transformations); err != nil {
+			return d, fmt.Errorf("decoding %s annotation: %w", kustomizeTransformationsAnnotation, err)
+		}
+	}
+
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(rendered, &doc); err != nil {
+		return d, fmt.Errorf("decoding built resource: %w", err)
+	}
+
+	var (
+		path         []kustomizePathElem
+		key, located *yaml.Node
+	)
+
+	if !isEmptyDocument(&doc) {
+		path, key, located = kustomizeNodeAt(doc.Content[0], d.Line, d.Column, nil)
+	}
+
+	// Later patches win
+	for i := len(transformations) - 1; i >= 0 && located != nil; i-- {
+		patches, err := k.patchFiles(r, transformations[i])
+		if err != nil {
+			return d, err
+		}
+
+		for _, p := range patches {
+			bs, err := os.ReadFile(filepath.Join(r.WorkDir, p))
+			if err != nil {
+				return d, fmt.Errorf("reading patch %s: %w", p, err)
+			}
+
+			if n := patchedNode(bs, resource.Kind, resource.Metadata.Name, path, key != nil, located); n != nil {
+				d.File, d.Line, d.Column = p, n.Line, n.Column
+
+				return d, nil
+			}
+		}
+	}
+
+	var origin kustomizeOrigin
+
+	if a := resource.Metadata.Annotations[kustomizeOriginAnnotation]; a != "" {
+		if err := yaml.Unmarshal([]byte(a), &origin); err != nil {
+			return d, fmt.Errorf("decoding %s annotation: %w", kustomizeOriginAnnotation, err)
+		}
+	}
+
+	source := origin.Path
+	if source == "" {
+		// Generated resources have no path but the kustomization that configured the generator
+		source = origin.ConfiguredIn
+	}
+
+	if source == "" {
+		d.File, d.Line, d.Column = k.kustomizationFile(r, k.Dir), 1, 1
+		d.Approximate = true
+
+		return d, nil
+	}
+
+	d.File = filepath.Join(k.Dir, source)
+
+	bs, err := os.ReadFile(filepath.Join(r.WorkDir, d.File))
+	if err != nil {
+		return d, fmt.Errorf("reading origin %s: %w", d.File, err)
+	}
+
+	line, col, ok := templatePosition(strings.Split(string(bs), "\n"), renderedLines, d.Line, d.Column)
+
+	d.Line, d.Column = line, col
+	d.Approximate = d.Approximate || !ok
+
+	return d, nil
+}
+
+// patchFiles returns the patch files in the kustomization that configured the patch transformer, relative to the runner's WorkDir.
+// Inline patches are not returned, so that diagnostics at lines patched by them are reported against the original resource
+func (k *KustomizeLinter) patchFiles(r *Runner, t kustomizeOrigin) ([]string, error) {
+	if !strings.HasPrefix(t.ConfiguredBy.Kind, "Patch") || t.ConfiguredIn == "" {
+		return nil, nil
+	}
+
+	file := filepath.Join(k.Dir, t.ConfiguredIn)
+
+	bs, err := os.ReadFile(filepath.Join(r.WorkDir, file))
+	if err != nil {
+		return nil, fmt.Errorf("reading kustomization %s: %w", file, err)
+	}
+
+	var kust kustomization
+
+	if err := yaml.Unmarshal(bs, &kust); err != nil {
+		return nil, fmt.Errorf("decoding kustomization %s: %w", file, err)
+	}
+
+	dir := filepath.Dir(file)
+
+	var paths []string
+
+	for _, p := range kust.Patches {
+		if p.Path != "" {
+			paths = append(paths, filepath.Join(dir, p.Path))
+		}
+	}
+
+	for _, p := range kust.PatchesStrategicMerge {
+		if !strings.Contains(p, "\n") {
+			paths = append(paths, filepath.Join(dir, p))
+		}
+	}
+
+	for _, p := range kust.PatchesJSON6902 {
+		if p.Path != "" {
+			paths = append(paths, filepath.Join(dir, p.Path))
+		}
+	}
+
+	return paths, nil
+}
+
+// kustomizePathElem is an element of the path from the root of a resource to a node
+type kustomizePathElem struct {
+	// Key is the key in the mapping, used unless Item is true
+	Key string
+	// Item is true when the element is the item at Index in a sequence
+	Item  bool
+	Index int
+	// Name is the name of the item, by which strategic-merge patches find the item to merge into
+	Name string
+}
+
+// kustomizeIdentityPaths are the JSON pointers to the fields identifying the resource to patch, rather than being patched
+var kustomizeIdentityPaths = map[string]bool{
+	"/apiVersion":         true,
+	"/kind":               true,
+	"/metadata/name":      true,
+	"/metadata/namespace": true,
+}
+
+// kustomizeNodeAt returns the path to the innermost node starting at the line and the column, along with the node.
+// The key is non-nil when the position is at the key of the node in the parent mapping.
+func kustomizeNodeAt(node *yaml.Node, line, col int, path []kustomizePathElem) ([]kustomizePathElem, *yaml.Node, *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			p := append(path[:len(path):len(path)], kustomizePathElem{Key: k.Value})
+
+			if found, key, n := kustomizeNodeAt(v, line, col, p); n != nil {
+				return found, key, n
+			}
+
+			if k.Line == line && k.Column == col {
+				return p, k, v
+			}
+
+			if v.Line == line && v.Column == col {
+				return p, nil, v
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			var name string
+
+			if item.Kind == yaml.MappingNode {
+				if n := mappingValue(item, "name"); n != nil {
+					name = n.Value
+				}
+			}
+
+			p := append(path[:len(path):len(path)], kustomizePathElem{Item: true, Index: i, Name: name})
+
+			if found, key, n := kustomizeNodeAt(item, line, col, p); n != nil {
+				return found, key, n
+			}
+
+			if item.Line == line && item.Column == col {
+				return p, nil, item
+			}
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// patchedNode returns the node in the patch that sets the node at the path to the same value as the located node in the built resource,
+// or nil when the patch doesn't set it.
+// The key of the node is returned instead when atKey is true.
+//
+// Both strategic-merge patches and JSON 6902 patches are supported.
+// Strategic-merge patches are matched against the resource by the kind and the name, which may lack the name prefix and suffix.
+func patchedNode(patch []byte, kind, name string, path []kustomizePathElem, atKey bool, located *yaml.Node) *yaml.Node {
+	ptr := kustomizeJSONPointer(path)
+
+	dec := yaml.NewDecoder(bytes.NewReader(patch))
+
+	for {
+		var doc yaml.Node
+
+		if err := dec.Decode(&doc); err != nil {
+			return nil
+		}
+
+		if isEmptyDocument(&doc) {
+			continue
+		}
+
+		root := doc.Content[0]
+
+		var key, value *yaml.Node
+
+		switch root.Kind {
+		case yaml.SequenceNode:
+			key, value = json6902Node(root, ptr, path)
+		case yaml.MappingNode:
+			if kustomizeIdentityPaths[ptr] || isMergeKey(path) || !patchTargets(root, kind, name) {
+				continue
+			}
+
+			key, value = strategicMergeNode(root, path)
+		}
+
+		if value == nil || !sameValue(value, located) {
+			continue
+		}
+
+		if atKey && key != nil {
+			return key
+		}
+
+		return value
+	}
+}
+
+// patchTargets returns true when the strategic-merge patch targets the resource of the kind and the name
+func patchTargets(patch *yaml.Node, kind, name string) bool {
+	if k := mappingValue(patch, "kind"); k != nil && k.Value != kind {
+		return false
+	}
+
+	if m := mappingValue(patch, "metadata"); m != nil {
+		if n := mappingValue(m, "name"); n != nil && !strings.Contains(name, n.Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isMergeKey returns true when the path is to the name of a named sequence item, which identifies the item rather than being patched
+func isMergeKey(path []kustomizePathElem) bool {
+	n := len(path)
+
+	return n >= 2 && !path[n-1].Item && path[n-1].Key == "name" && path[n-2].Item && path[n-2].Name != ""
+}
+
+// strategicMergeNode returns the node at the path in the strategic-merge patch, along with its key.
+// Sequence items having names are looked up by the names, as they are merged by the names rather than the indices.
+func strategicMergeNode(node *yaml.Node, path []kustomizePathElem) (*yaml.Node, *yaml.Node) {
+	var key *yaml.Node
+
+	for _, e := range path {
+		var next *yaml.Node
+
+		switch {
+		case !e.Item && node.Kind == yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == e.Key {
+					key, next = node.Content[i], node.Content[i+1]
+				}
+			}
+		case e.Item && node.Kind == yaml.SequenceNode && e.Name != "":
+			for _, item := range node.Content {
+				if n := mappingValue(item, "name"); n != nil && n.Value == e.Name {
+					key, next = nil, item
+				}
+			}
+		case e.Item && node.Kind == yaml.SequenceNode && e.Index < len(node.Content):
+			key, next = nil, node.Content[e.Index]
+		}
+
+		if next == nil {
+			return nil, nil
+		}
+
+		node = next
+	}
+
+	return key, node
+}
+
+// json6902Node returns the value of the last `add` or `replace` operation in the JSON 6902 patch setting the node at the JSON pointer or its ancestor,
+// along with the operation's path, which stands for the key
+func json6902Node(ops *yaml.Node, ptr string, path []kustomizePathElem) (*yaml.Node, *yaml.Node) {
+	for i := len(ops.Content) - 1; i >= 0; i-- {
+		op := ops.Content[i]
+
+		if op.Kind != yaml.MappingNode {
+			continue
+		}
+
+		o, p, v := mappingValue(op, "op"), mappingValue(op, "path"), mappingValue(op, "value")
+		if o == nil || (o.Value != "add" && o.Value != "replace") || p == nil || v == nil {
+			continue
+		}
+
+		if p.Value == ptr {
+			return p, v
+		}
+
+		if strings.HasPrefix(ptr, p.Value+"/") {
+			return strategicMergeNode(v, path[strings.Count(p.Value, "/"):])
+		}
+	}
+
+	return nil, nil
+}
+
+// kustomizeJSONPointer returns the JSON pointer like `/spec/template` to the node at the path
+func kustomizeJSONPointer(path []kustomizePathElem) string {
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+
+	var b strings.Builder
+
+	for _, e := range path {
+		b.WriteString("/")
+
+		if e.Item {
+			b.WriteString(strconv.Itoa(e.Index))
+		} else {
+			b.WriteString(escaper.Replace(e.Key))
+		}
+	}
+
+	return b.String()
+}
+
+// mappingValue returns the value for the key in the mapping, or nil if there's none
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// sameValue returns true when the nodes represent the same value regardless of their styles
+func sameValue(a, b *yaml.Node) bool {
+	var va, vb interface{}
+
+	if err := a.Decode(&va); err != nil {
+		return false
+	}
+
+	if err := b.Decode(&vb); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(va, vb)
+}
+
+// kustomizationFile returns the path to the kustomization file in the directory
+func (k *KustomizeLinter) kustomizationFile(r *Runner, dir string) string {
+	for _, name := range []string{"kustomization.yaml", "kustomization.yml", "Kustomization"} {
+		f := filepath.Join(dir, name)
+
+		if _, err := os.Stat(filepath.Join(r.WorkDir, f)); err == nil {
+			return f
+		}
+	}
+
+	return filepath.Join(dir, "kustomization.yaml")
+}
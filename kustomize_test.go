@@ -0,0 +1,154 @@
+package conflint
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestKustomize(t *testing.T) {
+	bin, err := filepath.Abs(filepath.Join("testdata", "kustomize", "bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tmp := t.TempDir()
+
+	t.Setenv("TMPDIR", tmp)
+
+	buf := &bytes.Buffer{}
+
+	runner := &Runner{
+		Output:     buf,
+		WorkDir:    filepath.Join("testdata", "kustomize"),
+		ConfigFile: "conflint.yaml",
+		Errformat:  "%f:%l:%c: %s: %m",
+		Delim:      ": ",
+	}
+
+	err = runner.Run(context.Background())
+	if err == nil || err.Error() != "found 1 linter error and 1 warning" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "base/deployment.yaml:1:13: warning: Too old apiVersion. It must be apps/v1\n" +
+		"overlays/prod/privileged.yaml:11:23: error: `privileged: true` is forbidden\n"
+
+	if d := cmp.Diff(want, buf.String()); d != "" {
+		t.Errorf("unexpected output: want (-), got (+):\n%s", d)
+	}
+
+	tmps, err := filepath.Glob(filepath.Join(tmp, "conflint-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tmps) > 0 {
+		t.Errorf("temporary directories are left: %v", tmps)
+	}
+}
+
+func TestPatchedNode(t *testing.T) {
+	built := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-hello
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: sidecar
+        image: envoy
+      - name: nginx
+        image: nginx:1.17.3
+        securityContext:
+          privileged: true
+`
+
+	strategicMerge := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: hello
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        securityContext:
+          privileged: true
+`
+
+	json6902 := `- op: replace
+  path: /spec/replicas
+  value: 3
+- op: add
+  path: /spec/template/spec/containers/1/securityContext
+  value:
+    privileged: true
+`
+
+	testcases := []struct {
+		patch     string
+		line, col int
+		wantLine  int
+		wantCol   int
+		wantFound bool
+	}{
+		// the value set by the patch
+		{patch: strategicMerge, line: 15, col: 23, wantLine: 11, wantCol: 23, wantFound: true},
+		// the key set by the patch
+		{patch: strategicMerge, line: 15, col: 11, wantLine: 11, wantCol: 11, wantFound: true},
+		// the fields identifying the resource
+		{patch: strategicMerge, line: 1, col: 13},
+		{patch: strategicMerge, line: 4, col: 9},
+		// the merge key of the item
+		{patch: strategicMerge, line: 12, col: 9},
+		// the value merged with the original resource
+		{patch: strategicMerge, line: 10, col: 7},
+		// the value replaced by the patch as a whole
+		{patch: strategicMerge, line: 14, col: 9, wantLine: 10, wantCol: 9, wantFound: true},
+		// the value not in the patch
+		{patch: strategicMerge, line: 13, col: 16},
+		{patch: json6902, line: 6, col: 13, wantLine: 3, wantCol: 10, wantFound: true},
+		{patch: json6902, line: 6, col: 3, wantLine: 2, wantCol: 9, wantFound: true},
+		{patch: json6902, line: 15, col: 23, wantLine: 7, wantCol: 17, wantFound: true},
+		{patch: json6902, line: 13, col: 16},
+	}
+
+	for _, tc := range testcases {
+		var doc yaml.Node
+
+		if err := yaml.Unmarshal([]byte(built), &doc); err != nil {
+			t.Fatal(err)
+		}
+
+		path, key, located := kustomizeNodeAt(doc.Content[0], tc.line, tc.col, nil)
+		if located == nil {
+			t.Fatalf("no node found at %d:%d", tc.line, tc.col)
+		}
+
+		n := patchedNode([]byte(tc.patch), "Deployment", "prod-hello", path, key != nil, located)
+
+		if !tc.wantFound {
+			if n != nil {
+				t.Errorf("unexpected node for %d:%d: got %d:%d", tc.line, tc.col, n.Line, n.Column)
+			}
+
+			continue
+		}
+
+		if n == nil {
+			t.Errorf("expected node for %d:%d: got none", tc.line, tc.col)
+		} else if n.Line != tc.wantLine || n.Column != tc.wantCol {
+			t.Errorf("unexpected node for %d:%d: want %d:%d, got %d:%d", tc.line, tc.col, tc.wantLine, tc.wantCol, n.Line, n.Column)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package conflint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Linter runs a linter as configured in an entry of the linter's section in the config file.
+//
+// A new Linter is created per config entry by the function registered via RegisterLinter.
+// Every Linter must also implement either CommandLinter or DiagnosticsLinter, which is checked while decoding the config.
+type Linter interface {
+	// DecodeConfig decodes the config entry into the linter
+	DecodeConfig(node *yaml.Node) error
+	// Expand returns groups of files to be linted, relative to the runner's WorkDir.
+	// The linter is executed once per group.
+	Expand(r *Runner) ([][]string, error)
+}
+
+// CommandLinter is a Linter that runs a command, like conftest, and converts its output into diagnostics
+type CommandLinter interface {
+	Linter
+	// Exec runs the linter against the files and returns the output.
+	// The linter must stop once ctx is done, returning a *TimeoutError when the deadline is exceeded.
+	Exec(ctx context.Context, r *Runner, files []string) ([]byte, error)
+	// Diagnostics converts the output from Exec into located diagnostics
+	Diagnostics(r *Runner, files []string, out []byte) ([]Diagnostic, error)
+}
+
+// DiagnosticsLinter is a Linter that returns located diagnostics by itself, like the ones linting in-process.
+// Lint is preferred over Exec and Diagnostics when a linter implements both interfaces.
+type DiagnosticsLinter interface {
+	Linter
+	// Lint lints the files and returns located diagnostics.
+	// The linter must stop once ctx is done, returning a *TimeoutError when the deadline is exceeded.
+	Lint(ctx context.Context, r *Runner, files []string) ([]Diagnostic, error)
+}
+
+// EntryConfig is the set of settings shared among all the config entries regardless of the linter
+type EntryConfig struct {
+	// FailOnWarn makes warnings from the linter result in a non-zero exit code
+	FailOnWarn bool `yaml:"failOnWarn"`
+	// Timeout is the maximum duration of each linter invocation, like `30s`. No timeout when zero
+	Timeout time.Duration `yaml:"timeout"`
+	// Anchor overrides the runner's Anchor for the diagnostics from the linter, like `key`
+	Anchor Anchor `yaml:"anchor"`
+}
+
+// TimeoutError is returned when a linter invocation didn't finish before the deadline
+type TimeoutError struct {
+	// Command is the command line that was running, or a description of what was running for linters that run in-process
+	Command string
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("timed out running `%s`", e.Command)
+}
+
+var (
+	lintersMu sync.RWMutex
+	linters   = map[string]func() Linter{}
+)
+
+func init() {
+	RegisterLinter("conftest", func() Linter { return &ConftestLinter{} })
+	RegisterLinter("custom", func() Linter { return &CustomLinter{} })
+	RegisterLinter("helm", func() Linter { return &HelmLinter{} })
+	RegisterLinter("kubeval", func() Linter { return &KubevalLinter{} })
+	RegisterLinter("kubeconform", func() Linter { return &KubeconformLinter{} })
+	RegisterLinter("kustomize", func() Linter { return &KustomizeLinter{} })
+	RegisterLinter("rego", func() Linter { return &RegoLinter{} })
+	RegisterLinter("schema", func() Linter { return &SchemaLinter{} })
+	RegisterLinter("yamllint", func() Linter { return &YamllintLinter{} })
+}
+
+// RegisterLinter makes the linter available under the top-level key `name` in the config file.
+// It replaces the linter previously registered with the same name, if any.
+func RegisterLinter(name string, new func() Linter) {
+	lintersMu.Lock()
+	defer lintersMu.Unlock()
+
+	linters[name] = new
+}
+
+// RegisteredLinters returns names of all the registered linters in alphabetical order
+func RegisteredLinters() []string {
+	lintersMu.RLock()
+	defer lintersMu.RUnlock()
+
+	var names []string
+
+	for name := range linters {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func newLinter(name string) (Linter, error) {
+	lintersMu.RLock()
+	new, ok := linters[name]
+	lintersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported linter %q: must be one of %s", name, strings.Join(RegisteredLinters(), ", "))
+	}
+
+	l := new()
+
+	switch l.(type) {
+	case DiagnosticsLinter, CommandLinter:
+	default:
+		return nil, fmt.Errorf("linter %q implements neither CommandLinter nor DiagnosticsLinter", name)
+	}
+
+	return l, nil
+}
+
+// linterEntry is a config entry decoded into the linter
+type linterEntry struct {
+	EntryConfig
+
+	linter Linter
+	result Result
+}
+
+// decodeConfig decodes the config file into linter entries, in the order of appearance
+func decodeConfig(bs []byte) ([]*linterEntry, error) {
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(bs, &doc); err != nil {
+		return nil, err
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	return decodeConfigNode(doc.Content[0])
+}
+
+// decodeConfigNode decodes the mapping from linter names to lists of config entries into linter entries
+func decodeConfigNode(root *yaml.Node) ([]*linterEntry, error) {
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: the config must be a mapping from linter names to lists of config entries", root.Line)
+	}
+
+	var entries []*linterEntry
+
+	for i := 0; i < len(root.Content); i += 2 {
+		name := root.Content[i].Value
+		section := root.Content[i+1]
+
+		if section.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("line %d: %s must be a list of config entries", section.Line, name)
+		}
+
+		for j, node := range section.Content {
+			linter, err := newLinter(name)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", root.Content[i].Line, err)
+			}
+
+			if err := linter.DecodeConfig(node); err != nil {
+				return nil, fmt.Errorf("decoding %s[%d]: %w", name, j, err)
+			}
+
+			entry := &linterEntry{
+				linter: linter,
+				result: Result{Linter: name, Name: fmt.Sprintf("%s[%d]", name, j)},
+			}
+
+			if err := node.Decode(&entry.EntryConfig); err != nil {
+				return nil, fmt.Errorf("decoding %s[%d]: %w", name, j, err)
+			}
+
+			entry.result.FailOnWarn = entry.FailOnWarn
+
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// globFiles returns files matching the pattern, relative to workDir
+func globFiles(workDir, pattern string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(workDir, pattern))
+	if err != nil {
+		return nil, fmt.Errorf("searching files matching %s: %w", pattern, err)
+	}
+
+	var fs []string
+
+	for _, f := range files {
+		// Patterns can point outside workDir, like the rendered manifests of nested linters in the temporary directory
+		if rel, err := filepath.Rel(workDir, f); err == nil {
+			f = rel
+		}
+
+		fs = append(fs, f)
+	}
+
+	return fs, nil
+}
+
+// globGroups returns the files matching the patterns relative to workDir, grouped per pattern, or per file when perFile is true.
+// Patterns matching nothing result in no group, so that no linter runs without files.
+func globGroups(workDir string, patterns []string, perFile bool) ([][]string, error) {
+	var groups [][]string
+
+	for _, p := range patterns {
+		fs, err := globFiles(workDir, p)
+		if err != nil {
+			return nil, err
+		}
+
+		if perFile {
+			for _, f := range fs {
+				groups = append(groups, []string{f})
+			}
+		} else if len(fs) > 0 {
+			groups = append(groups, fs)
+		}
+	}
+
+	return groups, nil
+}
+
+// commandWaitDelay is how long to wait for the output of a command killed on timeout.
+// Killing the command doesn't kill its children, like `sleep` run by a shell script, which keep the output open until they exit.
+const commandWaitDelay = time.Second
+
+// execCommand runs the command within the runner's WorkDir and returns the combined output.
+// A non-zero exit status is not an error, as linters usually exit non-zero when they found any problem.
+func execCommand(ctx context.Context, r *Runner, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("looking for executable: %q not found in PATH", name)
+	}
+
+	commandLine := strings.Join(append([]string{name}, args...), " ")
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = r.WorkDir
+	cmd.WaitDelay = commandWaitDelay
+	out, err := cmd.CombinedOutput()
+
+	switch ctx.Err() {
+	case nil:
+	case context.DeadlineExceeded:
+		return nil, &TimeoutError{Command: commandLine}
+	default:
+		return nil, fmt.Errorf("running %s: %w", commandLine, ctx.Err())
+	}
+
+	if err != nil && r.LogLevel == "DEBUG" {
+		fmt.Fprintf(os.Stderr, "DEBUG: running %s: %v\n", commandLine, err)
+	}
+
+	return out, nil
+}
+
+// renderCommand runs the command that renders manifests, like `helm template`, within the runner's WorkDir and returns the standard output.
+// Unlike execCommand, a non-zero exit status is an error containing the standard error.
+func renderCommand(ctx context.Context, r *Runner, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("looking for executable: %q not found in PATH", name)
+	}
+
+	commandLine := strings.Join(append([]string{name}, args...), " ")
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = r.WorkDir
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = commandWaitDelay
+	out, err := cmd.Output()
+
+	switch ctx.Err() {
+	case nil:
+	case context.DeadlineExceeded:
+		return nil, &TimeoutError{Command: commandLine}
+	default:
+		return nil, fmt.Errorf("running %s: %w", commandLine, ctx.Err())
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("running %s: %w: %s", commandLine, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
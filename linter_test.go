@@ -0,0 +1,273 @@
+package conflint
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// echoLinter reports the configured messages as warnings for every file
+type echoLinter struct {
+	Files    []string `yaml:"files"`
+	Messages []string `yaml:"messages"`
+}
+
+func (e *echoLinter) DecodeConfig(node *yaml.Node) error {
+	return node.Decode(e)
+}
+
+func (e *echoLinter) Expand(r *Runner) ([][]string, error) {
+	return globGroups(r.WorkDir, e.Files, false)
+}
+
+func (e *echoLinter) Exec(ctx context.Context, r *Runner, files []string) ([]byte, error) {
+	var lines []string
+
+	for _, f := range files {
+		for _, m := range e.Messages {
+			lines = append(lines, f+": "+m)
+		}
+	}
+
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+func (e *echoLinter) Diagnostics(r *Runner, files []string, out []byte) ([]Diagnostic, error) {
+	var diags []Diagnostic
+
+	for _, line := range strings.Split(string(out), "\n") {
+		sub := strings.SplitN(line, ": ", 3)
+
+		ds, err := r.Locate(sub[0], "$."+sub[1])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range ds {
+			d.Linter = "echo"
+			d.Severity = SeverityWarning
+			d.Message = sub[2]
+
+			diags = append(diags, d)
+		}
+	}
+
+	return diags, nil
+}
+
+func init() {
+	RegisterLinter("echo", func() Linter { return &echoLinter{} })
+}
+
+func TestRegisterLinter(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	runner := &Runner{
+		Output:     buf,
+		WorkDir:    filepath.Join("testdata", "registry"),
+		ConfigFile: "conflint.yaml",
+		Errformat:  "%f:%l:%c: %s: %m",
+		Delim:      ": ",
+	}
+
+	err := runner.Run(context.Background())
+	if err == nil {
+		t.Fatal("expected error: want found 1 linter warning, got none")
+	}
+
+	if diff := cmp.Diff("found 1 linter warning", err.Error()); diff != "" {
+		t.Errorf("unexpected error: %s", diff)
+	}
+
+	want := "app1/nginx.deploy.yaml:4:9: warning: name must be prefixed with the team name\n"
+
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("unexpected output: %s", diff)
+	}
+}
+
+func TestDecodeConfigUnsupportedLinter(t *testing.T) {
+	_, err := decodeConfig([]byte("nonexistent:\n- files: [a.yaml]\n"))
+	if err == nil {
+		t.Fatal("expected error: got none")
+	}
+
+	if !strings.Contains(err.Error(), `unsupported linter "nonexistent"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// severitylessLinter reports a diagnostic without the severity at the top of every file
+type severitylessLinter struct {
+	Files []string `yaml:"files"`
+}
+
+func (l *severitylessLinter) DecodeConfig(node *yaml.Node) error {
+	return node.Decode(l)
+}
+
+func (l *severitylessLinter) Expand(r *Runner) ([][]string, error) {
+	return globGroups(r.WorkDir, l.Files, false)
+}
+
+func (l *severitylessLinter) Lint(ctx context.Context, r *Runner, files []string) ([]Diagnostic, error) {
+	var diags []Diagnostic
+
+	for _, f := range files {
+		diags = append(diags, Diagnostic{Linter: "severityless", File: f, Line: 1, Column: 1, Message: "something is wrong"})
+	}
+
+	return diags, nil
+}
+
+func TestRunJobEmptySeverity(t *testing.T) {
+	RegisterLinter("severityless", func() Linter { return &severitylessLinter{} })
+
+	entries, err := decodeConfig([]byte("severityless:\n- files: [app1/*.yaml]\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	runner := &Runner{WorkDir: filepath.Join("testdata", "registry")}
+
+	results, err := runner.lintEntries(context.Background(), entries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Diagnostic{
+		{Linter: "severityless", Severity: SeverityError, File: "app1/nginx.deploy.yaml", Line: 1, Column: 1, Message: "something is wrong"},
+	}
+
+	if diff := cmp.Diff(want, results[0].Diagnostics); diff != "" {
+		t.Errorf("unexpected diagnostics: %s", diff)
+	}
+}
+
+// incompleteLinter can't run, as it implements neither CommandLinter nor DiagnosticsLinter
+type incompleteLinter struct{}
+
+func (l *incompleteLinter) DecodeConfig(node *yaml.Node) error { return nil }
+
+func (l *incompleteLinter) Expand(r *Runner) ([][]string, error) { return nil, nil }
+
+func TestDecodeConfigIncompleteLinter(t *testing.T) {
+	RegisterLinter("incomplete", func() Linter { return &incompleteLinter{} })
+
+	_, err := decodeConfig([]byte("incomplete:\n- files: [a.yaml]\n"))
+	if err == nil {
+		t.Fatal("expected error: got none")
+	}
+
+	if !strings.Contains(err.Error(), `linter "incomplete" implements neither CommandLinter nor DiagnosticsLinter`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestExecCommandTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := execCommand(ctx, &Runner{}, "sleep", "5")
+
+	var timeoutErr *TimeoutError
+
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected timeout error: got %v", err)
+	}
+
+	if diff := cmp.Diff("timed out running `sleep 5`", err.Error()); diff != "" {
+		t.Errorf("unexpected error: %s", diff)
+	}
+}
+
+func TestExecCommandTimeoutWithChildren(t *testing.T) {
+	for name, run := range map[string]func(context.Context, *Runner, string, ...string) ([]byte, error){
+		"execCommand":   execCommand,
+		"renderCommand": renderCommand,
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+
+		start := time.Now()
+
+		// The shell leaves the sleep holding the output after being killed
+		_, err := run(ctx, &Runner{}, "sh", "-c", "sleep 20; echo hi")
+
+		cancel()
+
+		var timeoutErr *TimeoutError
+
+		if !errors.As(err, &timeoutErr) {
+			t.Errorf("%s: expected timeout error: got %v", name, err)
+		}
+
+		if d := time.Since(start); d > 5*time.Second {
+			t.Errorf("%s: took %v to time out", name, d)
+		}
+	}
+}
+
+func TestDecodeConfigTimeout(t *testing.T) {
+	entries, err := decodeConfig([]byte("kubeval:\n- files: [a.yaml]\n  timeout: 30s\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if entries[0].Timeout != 30*time.Second {
+		t.Errorf("unexpected timeout: want 30s, got %v", entries[0].Timeout)
+	}
+
+	if k := entries[0].linter.(*KubevalLinter); k.Timeout != 30*time.Second {
+		t.Errorf("unexpected timeout in kubeval config: want 30s, got %v", k.Timeout)
+	}
+}
+
+func TestDecodeConfigAnchor(t *testing.T) {
+	entries, err := decodeConfig([]byte("conftest:\n- files: [a.yaml]\n  anchor: key\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if entries[0].Anchor != AnchorKey {
+		t.Errorf("unexpected anchor: want %q, got %q", AnchorKey, entries[0].Anchor)
+	}
+
+	_, err = decodeConfig([]byte("conftest:\n- files: [a.yaml]\n  anchor: name\n"))
+	if err == nil {
+		t.Fatal("expected error: got none")
+	}
+
+	if !strings.Contains(err.Error(), `unsupported anchor "name"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestGlobGroups(t *testing.T) {
+	workDir := filepath.Join("testdata", "parallel")
+
+	testcases := []struct {
+		perFile bool
+		want    [][]string
+	}{
+		{perFile: false, want: [][]string{{"app1/a.yaml", "app1/b.yaml", "app1/c.yaml"}}},
+		{perFile: true, want: [][]string{{"app1/a.yaml"}, {"app1/b.yaml"}, {"app1/c.yaml"}}},
+	}
+
+	for _, tc := range testcases {
+		groups, err := globGroups(workDir, []string{"app1/*.yaml", "nonexistent/*.yaml"}, tc.perFile)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if diff := cmp.Diff(tc.want, groups); diff != "" {
+			t.Errorf("unexpected groups with perFile=%v: %s", tc.perFile, diff)
+		}
+	}
+}
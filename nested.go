@@ -0,0 +1,257 @@
+package conflint
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// lintRendered runs the nested linters against the rendered files, keyed by paths like `mychart/templates/deployment.yaml`,
+// and returns the diagnostics with File being one of the keys.
+//
+// The files are written into a temporary directory outside the runner's WorkDir, so that nothing is left in the repository even if the process is killed.
+// The nested linters run within the runner's WorkDir, so that relative paths in the nested config, like conftest policies and schema locations,
+// are resolved in the same way as the top-level config, while the rendered files are referred to by the paths relative to WorkDir.
+// Every nested config entry lints all the rendered files, or the files matching its `files` patterns relative to the temporary directory.
+func lintRendered(ctx context.Context, r *Runner, name string, linters *yaml.Node, rendered map[string][]byte) ([]Diagnostic, error) {
+	dir, err := os.MkdirTemp("", "conflint-"+name+"-")
+	if err != nil {
+		return nil, fmt.Errorf("creating temporary directory: %w", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	wd, err := filepath.Abs(r.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := filepath.Rel(wd, dir)
+	if err != nil {
+		return nil, fmt.Errorf("referring to temporary directory %s from %s: %w", dir, r.WorkDir, err)
+	}
+
+	var paths []string
+
+	for p := range rendered {
+		paths = append(paths, p)
+	}
+
+	sort.Strings(paths)
+
+	var files []string
+
+	for _, p := range paths {
+		f := filepath.Join(dir, p)
+
+		if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+			return nil, err
+		}
+
+		if err := os.WriteFile(f, rendered[p], 0644); err != nil {
+			return nil, err
+		}
+
+		files = append(files, filepath.Join(prefix, p))
+	}
+
+	config, err := nestedConfig(linters, prefix, files)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := decodeConfigNode(config)
+	if err != nil {
+		return nil, fmt.Errorf("decoding linters: %w", err)
+	}
+
+	// The nested linters run one at a time within the job of the outer linter, so that `-j N` still limits the whole run to N invocations
+	nested := &Runner{
+		WorkDir:        r.WorkDir,
+		Delim:          r.Delim,
+		LogLevel:       r.LogLevel,
+		Concurrency:    1,
+		FirstMatchOnly: r.FirstMatchOnly,
+		StrictPaths:    r.StrictPaths,
+		Anchor:         r.Anchor,
+	}
+
+	results, err := nested.lintEntries(ctx, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	var diags []Diagnostic
+
+	for _, res := range results {
+		for _, d := range res.Diagnostics {
+			d.File = strings.TrimPrefix(d.File, prefix+string(filepath.Separator))
+			d.FailOnWarn = d.FailOnWarn || res.FailOnWarn
+
+			diags = append(diags, d)
+		}
+	}
+
+	return diags, nil
+}
+
+// nestedConfig returns a copy of the nested linters config with the `files` of every entry pointing to the rendered files
+func nestedConfig(linters *yaml.Node, prefix string, files []string) (*yaml.Node, error) {
+	bs, err := yaml.Marshal(linters)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(bs, &doc); err != nil {
+		return nil, err
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: linters must be a mapping from linter names to lists of config entries", linters.Line)
+	}
+
+	root := doc.Content[0]
+
+	for i := 1; i < len(root.Content); i += 2 {
+		for _, entry := range root.Content[i].Content {
+			if entry.Kind != yaml.MappingNode {
+				continue
+			}
+
+			var patterns *yaml.Node
+
+			for j := 0; j < len(entry.Content); j += 2 {
+				if entry.Content[j].Value == "files" {
+					patterns = entry.Content[j+1]
+				}
+			}
+
+			if patterns != nil {
+				for _, p := range patterns.Content {
+					p.Value = filepath.Join(prefix, p.Value)
+				}
+
+				continue
+			}
+
+			patterns = &yaml.Node{Kind: yaml.SequenceNode}
+
+			for _, f := range files {
+				patterns.Content = append(patterns.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f})
+			}
+
+			entry.Content = append(entry.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "files"}, patterns)
+		}
+	}
+
+	return root, nil
+}
+
+// templatePosition returns the position in the template corresponding to the line and the column in the rendered manifest.
+// It returns the top of the template and false when nothing corresponds.
+func templatePosition(tmplLines, renderedLines []string, line, col int) (int, int, bool) {
+	if line < 1 || line > len(renderedLines) {
+		return 1, 1, false
+	}
+
+	text := renderedLines[line-1]
+	trimmed := strings.TrimSpace(text)
+
+	if trimmed == "" {
+		return 1, 1, false
+	}
+
+	if l, c, ok := identicalLine(tmplLines, renderedLines, line, col); ok {
+		return l, c, true
+	}
+
+	key := strings.TrimPrefix(trimmed, "- ")
+
+	i := strings.Index(key, ":")
+	if i <= 0 {
+		return 1, 1, false
+	}
+
+	key = key[:i+1]
+
+	l := nearestLine(tmplLines, line, func(l string) bool { return strings.HasPrefix(strings.TrimPrefix(strings.TrimSpace(l), "- "), key) })
+	if l == 0 {
+		return 1, 1, false
+	}
+
+	tmpl := tmplLines[l-1]
+	c := strings.Index(tmpl, key)
+
+	// Keep the diagnostic at the key when it's anchored at the key in the rendered line, and otherwise move it to the value,
+	// which is often a template action like `{{ .Values.foo }}` rendering the problematic value
+	if col-1 < strings.Index(text, key)+len(key) {
+		return l, c + 1, true
+	}
+
+	v := c + len(key)
+	for v < len(tmpl) && tmpl[v] == ' ' {
+		v++
+	}
+
+	if v < len(tmpl) {
+		return l, v + 1, true
+	}
+
+	return l, c + 1, true
+}
+
+// identicalLine returns the position in the template line identical to the rendered line, ignoring indentation
+func identicalLine(tmplLines, renderedLines []string, line, col int) (int, int, bool) {
+	if line < 1 || line > len(renderedLines) {
+		return 0, 0, false
+	}
+
+	text := renderedLines[line-1]
+	trimmed := strings.TrimSpace(text)
+
+	if trimmed == "" {
+		return 0, 0, false
+	}
+
+	l := nearestLine(tmplLines, line, func(l string) bool { return strings.TrimSpace(l) == trimmed })
+	if l == 0 {
+		return 0, 0, false
+	}
+
+	indent := func(s string) int { return len(s) - len(strings.TrimLeft(s, " \t")) }
+
+	c := col - indent(text) + indent(tmplLines[l-1])
+	if c < 1 {
+		c = 1
+	}
+
+	return l, c, true
+}
+
+// nearestLine returns the number of the matching line nearest to the line, or 0 if none matched
+func nearestLine(lines []string, line int, match func(string) bool) int {
+	found := 0
+
+	for i, l := range lines {
+		if match(l) && (found == 0 || abs(i+1-line) < abs(found-line)) {
+			found = i + 1
+		}
+	}
+
+	return found
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+
+	return i
+}
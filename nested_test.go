@@ -0,0 +1,87 @@
+package conflint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestLintRenderedFailOnWarn(t *testing.T) {
+	config := `
+echo:
+- messages: ["kind: kind is deprecated"]
+  failOnWarn: true
+- messages: ["metadata: metadata is incomplete"]
+`
+
+	var linters yaml.Node
+
+	if err := yaml.Unmarshal([]byte(config), &linters); err != nil {
+		t.Fatal(err)
+	}
+
+	rendered := map[string][]byte{
+		"mychart/templates/cm.yaml": []byte("kind: ConfigMap\nmetadata:\n  name: hello\n"),
+	}
+
+	diags, err := lintRendered(context.Background(), &Runner{WorkDir: t.TempDir(), Concurrency: 4}, "test", linters.Content[0], rendered)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Diagnostic{
+		{Linter: "echo", Severity: SeverityWarning, File: "mychart/templates/cm.yaml", Line: 1, Column: 7, EndLine: 1, EndColumn: 16, Message: "kind is deprecated", FailOnWarn: true},
+		{Linter: "echo", Severity: SeverityWarning, File: "mychart/templates/cm.yaml", Line: 3, Column: 3, EndLine: 3, EndColumn: 14, Message: "metadata is incomplete"},
+	}
+
+	if d := cmp.Diff(want, diags); d != "" {
+		t.Errorf("unexpected diagnostics: want (-), got (+):\n%s", d)
+	}
+}
+
+func TestTemplatePosition(t *testing.T) {
+	tmplLines := []string{
+		"apiVersion: {{ .Values.apiVersion }}",
+		"kind: Deployment",
+		"spec:",
+		"  replicas: {{ .Values.replicas }}",
+		"  template: {{- include \"pod\" . | nindent 4 }}",
+	}
+
+	renderedLines := []string{
+		"apiVersion: apps/v1",
+		"kind: Deployment",
+		"spec:",
+		"  replicas: 3",
+		"  template:",
+		"    metadata:",
+	}
+
+	testcases := []struct {
+		line, col int
+		wantLine  int
+		wantCol   int
+		wantOK    bool
+	}{
+		// identical lines
+		{line: 2, col: 7, wantLine: 2, wantCol: 7, wantOK: true},
+		// anchored at the value
+		{line: 1, col: 13, wantLine: 1, wantCol: 13, wantOK: true},
+		{line: 4, col: 13, wantLine: 4, wantCol: 13, wantOK: true},
+		// anchored at the key
+		{line: 1, col: 1, wantLine: 1, wantCol: 1, wantOK: true},
+		{line: 4, col: 3, wantLine: 4, wantCol: 3, wantOK: true},
+		// rendered from a helper template
+		{line: 6, col: 5, wantLine: 1, wantCol: 1, wantOK: false},
+	}
+
+	for _, tc := range testcases {
+		l, c, ok := templatePosition(tmplLines, renderedLines, tc.line, tc.col)
+
+		if l != tc.wantLine || c != tc.wantCol || ok != tc.wantOK {
+			t.Errorf("unexpected position for %d:%d: want %d:%d %v, got %d:%d %v", tc.line, tc.col, tc.wantLine, tc.wantCol, tc.wantOK, l, c, ok)
+		}
+	}
+}
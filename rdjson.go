@@ -0,0 +1,106 @@
+package conflint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// RDJSONReporter prints diagnostics in reviewdog's Diagnostic format.
+// See https://github.com/reviewdog/reviewdog/tree/master/proto/rdf for the format.
+type RDJSONReporter struct {
+	// JSONLines prints every diagnostic in a line as rdjsonl, instead of a single rdjson DiagnosticResult
+	JSONLines bool
+}
+
+type rdjsonResult struct {
+	Source      rdjsonSource       `json:"source"`
+	Diagnostics []rdjsonDiagnostic `json:"diagnostics"`
+}
+
+type rdjsonDiagnostic struct {
+	Message  string         `json:"message"`
+	Location rdjsonLocation `json:"location"`
+	Severity string         `json:"severity"`
+	Source   rdjsonSource   `json:"source"`
+	Code     *rdjsonCode    `json:"code,omitempty"`
+}
+
+type rdjsonSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type rdjsonCode struct {
+	Value string `json:"value"`
+}
+
+type rdjsonLocation struct {
+	Path  string      `json:"path"`
+	Range rdjsonRange `json:"range"`
+}
+
+type rdjsonRange struct {
+	Start rdjsonPosition  `json:"start"`
+	End   *rdjsonPosition `json:"end,omitempty"`
+}
+
+type rdjsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (r *RDJSONReporter) Report(w io.Writer, results []Result) error {
+	diags := []rdjsonDiagnostic{}
+
+	for _, res := range results {
+		for _, d := range res.Diagnostics {
+			diag := rdjsonDiagnostic{
+				Message: d.Message,
+				Location: rdjsonLocation{
+					Path: filepath.ToSlash(d.File),
+					Range: rdjsonRange{
+						Start: rdjsonPosition{Line: d.Line, Column: d.Column},
+					},
+				},
+				Severity: strings.ToUpper(string(d.Severity)),
+				Source: rdjsonSource{
+					Name: d.Linter,
+					URL:  linterInformationURIs[d.Linter],
+				},
+			}
+
+			if d.EndLine > 0 {
+				diag.Location.Range.End = &rdjsonPosition{Line: d.EndLine, Column: d.EndColumn}
+			}
+
+			if d.Rule != "" {
+				diag.Code = &rdjsonCode{Value: d.Rule}
+			}
+
+			diags = append(diags, diag)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+
+	if r.JSONLines {
+		for _, d := range diags {
+			if err := enc.Encode(d); err != nil {
+				return fmt.Errorf("encoding rdjsonl: %w", err)
+			}
+		}
+
+		return nil
+	}
+
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(rdjsonResult{Source: rdjsonSource{Name: "conflint"}, Diagnostics: diags}); err != nil {
+		return fmt.Errorf("encoding rdjson: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package conflint
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRDJSONReporter(t *testing.T) {
+	results := []Result{
+		{
+			Linter: "conftest",
+			Files:  []string{"app1/nginx.deploy.yaml"},
+			Diagnostics: []Diagnostic{
+				{
+					Linter:    "conftest",
+					Rule:      "deny",
+					Severity:  SeverityError,
+					File:      "app1/nginx.deploy.yaml",
+					Line:      15,
+					Column:    11,
+					EndLine:   18,
+					EndColumn: 29,
+					Message:   "`privileged: true` is forbidden",
+				},
+			},
+		},
+		{
+			Linter: "kubeval",
+			Files:  []string{"app1/nginx.deploy.yaml"},
+			Diagnostics: []Diagnostic{
+				{
+					Linter:    "kubeval",
+					Rule:      "schema",
+					Severity:  SeverityError,
+					File:      "app1/nginx.deploy.yaml",
+					Line:      18,
+					Column:    25,
+					EndLine:   18,
+					EndColumn: 30,
+					Message:   "Invalid type. Expected: [boolean,null], given: string",
+				},
+			},
+		},
+	}
+
+	testcases := []struct {
+		jsonLines bool
+		want      string
+	}{
+		{
+			jsonLines: true,
+			want: `{"message":"` + "`privileged: true` is forbidden" + `","location":{"path":"app1/nginx.deploy.yaml","range":{"start":{"line":15,"column":11},"end":{"line":18,"column":29}}},"severity":"ERROR","source":{"name":"conftest","url":"https://github.com/open-policy-agent/conftest"},"code":{"value":"deny"}}
+{"message":"Invalid type. Expected: [boolean,null], given: string","location":{"path":"app1/nginx.deploy.yaml","range":{"start":{"line":18,"column":25},"end":{"line":18,"column":30}}},"severity":"ERROR","source":{"name":"kubeval","url":"https://github.com/instrumenta/kubeval"},"code":{"value":"schema"}}
+`,
+		},
+		{
+			jsonLines: false,
+			want: `{
+  "source": {
+    "name": "conflint"
+  },
+  "diagnostics": [
+    {
+      "message": "` + "`privileged: true` is forbidden" + `",
+      "location": {
+        "path": "app1/nginx.deploy.yaml",
+        "range": {
+          "start": {
+            "line": 15,
+            "column": 11
+          },
+          "end": {
+            "line": 18,
+            "column": 29
+          }
+        }
+      },
+      "severity": "ERROR",
+      "source": {
+        "name": "conftest",
+        "url": "https://github.com/open-policy-agent/conftest"
+      },
+      "code": {
+        "value": "deny"
+      }
+    },
+    {
+      "message": "Invalid type. Expected: [boolean,null], given: string",
+      "location": {
+        "path": "app1/nginx.deploy.yaml",
+        "range": {
+          "start": {
+            "line": 18,
+            "column": 25
+          },
+          "end": {
+            "line": 18,
+            "column": 30
+          }
+        }
+      },
+      "severity": "ERROR",
+      "source": {
+        "name": "kubeval",
+        "url": "https://github.com/instrumenta/kubeval"
+      },
+      "code": {
+        "value": "schema"
+      }
+    }
+  ]
+}
+`,
+		},
+	}
+
+	for _, tc := range testcases {
+		buf := &bytes.Buffer{}
+
+		if err := (&RDJSONReporter{JSONLines: tc.jsonLines}).Report(buf, results); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if diff := cmp.Diff(tc.want, buf.String()); diff != "" {
+			t.Errorf("unexpected output with jsonLines=%v: %s", tc.jsonLines, diff)
+		}
+	}
+}
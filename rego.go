@@ -0,0 +1,298 @@
+package conflint
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/loader"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/open-policy-agent/opa/storage/inmem"
+	yaml "gopkg.in/yaml.v3"
+)
+
+type RegoConfig struct {
+	EntryConfig `yaml:",inline"`
+
+	Files         []string `yaml:"files"`
+	Policy        string   `yaml:"policy"`
+	Data          []string `yaml:"data"`
+	AllNamespaces bool     `yaml:"allNamespaces"`
+	Namespaces    []string `yaml:"namespaces"`
+}
+
+// RegoLinter evaluates Rego policies against every YAML document in the files in-process,
+// in the same way as conftest does without the conftest binary.
+//
+// Rules named `deny`, `violation`, `warn` or prefixed with `deny_`, `violation_` or `warn_` are evaluated, and must be sets of messages.
+// Every message is expected to start with a jsonpath expression followed by the runner's Delim, as with ConftestLinter.
+type RegoLinter struct {
+	RegoConfig
+
+	// queries are prepared in Expand, so that every invocation shares them
+	queries []regoQuery
+}
+
+type regoQuery struct {
+	query    string
+	severity Severity
+	prepared rego.PreparedEvalQuery
+}
+
+func (l *RegoLinter) DecodeConfig(node *yaml.Node) error {
+	return node.Decode(&l.RegoConfig)
+}
+
+func (l *RegoLinter) Expand(r *Runner) ([][]string, error) {
+	// No deadline applies here, as the timeout of a linter invocation shouldn't fail the others sharing the queries
+	queries, err := l.prepare(context.Background(), r)
+	if err != nil {
+		return nil, err
+	}
+
+	l.queries = queries
+
+	return globGroups(r.WorkDir, l.Files, true)
+}
+
+// Lint evaluates the policies against the files and locates the messages in the same way as ConftestLinter
+func (l *RegoLinter) Lint(ctx context.Context, r *Runner, files []string) ([]Diagnostic, error) {
+	var (
+		out   ConftestOutput
+		diags []Diagnostic
+	)
+
+	for _, f := range files {
+		docs, err := ReadYAMLFiles(filepath.Join(r.WorkDir, f))
+		if err != nil {
+			// Like conftest, a file that can't be parsed fails alone
+			diags = append(diags, parseErrorDiagnostic("rego", f, err))
+
+			continue
+		}
+
+		res := ConftestFileResult{Filename: f}
+
+		for _, nodes := range docs {
+			for i, doc := range nodes {
+				if isEmptyDocument(&doc) {
+					continue
+				}
+
+				var input interface{}
+
+				if err := doc.Content[0].Decode(&input); err != nil {
+					return nil, fmt.Errorf("decoding %s: %w", f, err)
+				}
+
+				for _, q := range l.queries {
+					results, err := evalRegoQuery(ctx, q, input)
+					if err != nil {
+						if ctx.Err() == context.DeadlineExceeded {
+							return nil, &TimeoutError{Command: fmt.Sprintf("%s against %s", q.query, f)}
+						}
+						return nil, err
+					}
+
+					for _, result := range results {
+						index := i
+						result.Document = &index
+
+						switch q.severity {
+						case SeverityWarning:
+							res.Warnings = append(res.Warnings, result)
+						default:
+							res.Failures = append(res.Failures, result)
+						}
+					}
+				}
+			}
+		}
+
+		out = append(out, res)
+	}
+
+	ds, err := conftestDiagnostics(r, "rego", out)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(diags, ds...), nil
+}
+
+// prepare loads the policies and the data, and prepares queries for all the rules to be evaluated
+func (l *RegoLinter) prepare(ctx context.Context, r *Runner) ([]regoQuery, error) {
+	policies, err := loader.AllRegos([]string{filepath.Join(r.WorkDir, l.Policy)})
+	if err != nil {
+		return nil, fmt.Errorf("loading policies from %s: %w", l.Policy, err)
+	}
+
+	compiler, err := policies.Compiler()
+	if err != nil {
+		return nil, fmt.Errorf("compiling policies from %s: %w", l.Policy, err)
+	}
+
+	store := inmem.New()
+
+	if len(l.Data) > 0 {
+		var paths []string
+
+		for _, d := range l.Data {
+			paths = append(paths, filepath.Join(r.WorkDir, d))
+		}
+
+		data, err := loader.All(paths)
+		if err != nil {
+			return nil, fmt.Errorf("loading data from %s: %w", strings.Join(l.Data, ", "), err)
+		}
+
+		store, err = data.Store()
+		if err != nil {
+			return nil, fmt.Errorf("loading data from %s: %w", strings.Join(l.Data, ", "), err)
+		}
+	}
+
+	namespaces := map[string]bool{}
+
+	if l.AllNamespaces {
+		for _, m := range policies.ParsedModules() {
+			namespaces[strings.TrimPrefix(m.Package.Path.String(), "data.")] = true
+		}
+	} else if len(l.Namespaces) > 0 {
+		for _, ns := range l.Namespaces {
+			namespaces[ns] = true
+		}
+	} else {
+		namespaces["main"] = true
+	}
+
+	rules := map[string]Severity{}
+
+	for _, m := range policies.ParsedModules() {
+		ns := strings.TrimPrefix(m.Package.Path.String(), "data.")
+
+		if !namespaces[ns] {
+			continue
+		}
+
+		for _, rule := range m.Rules {
+			name := rule.Head.Ref()[0].Value.String()
+
+			severity, ok := regoRuleSeverity(name)
+			if !ok {
+				continue
+			}
+
+			// Rules like `deny { ... }` have no message to locate
+			if rule.Head.RuleKind() != ast.MultiValue {
+				return nil, fmt.Errorf("%s: rule %s must be a set of messages like `%s[msg] { ... }`", rule.Location, name, name)
+			}
+
+			rules[fmt.Sprintf("data.%s.%s", ns, name)] = severity
+		}
+	}
+
+	var names []string
+
+	for name := range rules {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var queries []regoQuery
+
+	for _, name := range names {
+		prepared, err := prepareRegoQuery(ctx, name, compiler, store)
+		if err != nil {
+			return nil, err
+		}
+
+		queries = append(queries, regoQuery{query: name, severity: rules[name], prepared: prepared})
+	}
+
+	return queries, nil
+}
+
+func prepareRegoQuery(ctx context.Context, query string, compiler *ast.Compiler, store storage.Store) (rego.PreparedEvalQuery, error) {
+	prepared, err := rego.New(
+		rego.Query(query),
+		rego.Compiler(compiler),
+		rego.Store(store),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return prepared, fmt.Errorf("preparing %s: %w", query, err)
+	}
+
+	return prepared, nil
+}
+
+// regoRuleSeverity returns the severity of diagnostics from the rule, or false if the rule isn't the one to be evaluated
+func regoRuleSeverity(name string) (Severity, bool) {
+	for prefix, severity := range map[string]Severity{
+		"deny":      SeverityError,
+		"violation": SeverityError,
+		"warn":      SeverityWarning,
+	} {
+		if name == prefix || strings.HasPrefix(name, prefix+"_") {
+			return severity, true
+		}
+	}
+
+	return "", false
+}
+
+// evalRegoQuery evaluates the query and returns results from the resulting set.
+// Every element of the set is either a message string or an object that has the message at the `msg` key and the metadata at the other keys.
+func evalRegoQuery(ctx context.Context, q regoQuery, input interface{}) ([]ConftestResult, error) {
+	rs, err := q.prepared.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, fmt.Errorf("evaluating %s: %w", q.query, err)
+	}
+
+	var results []ConftestResult
+
+	for _, result := range rs {
+		for _, expr := range result.Expressions {
+			values, ok := expr.Value.([]interface{})
+			if !ok {
+				continue
+			}
+
+			for _, v := range values {
+				switch typed := v.(type) {
+				case string:
+					results = append(results, ConftestResult{Msg: typed})
+				case map[string]interface{}:
+					msg, ok := typed["msg"].(string)
+					if !ok {
+						continue
+					}
+
+					// Like conftest, the other keys are the metadata
+					var metadata map[string]interface{}
+
+					for k, v := range typed {
+						if k == "msg" {
+							continue
+						}
+
+						if metadata == nil {
+							metadata = map[string]interface{}{}
+						}
+
+						metadata[k] = v
+					}
+
+					results = append(results, ConftestResult{Msg: msg, Metadata: metadata})
+				}
+			}
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,56 @@
+package conflint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegoLintAfterTimeout(t *testing.T) {
+	runner := &Runner{WorkDir: filepath.Join("testdata", "rego"), Delim: ": "}
+
+	l := &RegoLinter{RegoConfig: RegoConfig{Files: []string{"app1/nginx.deploy.yaml"}, Policy: "app1/policy"}}
+
+	groups, err := l.Expand(runner)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The cancellation of one invocation doesn't affect the others, whether or not it failed
+	_, _ = l.Lint(ctx, runner, groups[0])
+
+	diags, err := l.Lint(context.Background(), runner, groups[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(diags) != 2 {
+		t.Errorf("unexpected diagnostics: %+v", diags)
+	}
+}
+
+func TestRegoBooleanRule(t *testing.T) {
+	dir := t.TempDir()
+
+	policy := "package main\n\ndeny {\n  input.kind == \"Deployment\"\n}\n"
+
+	if err := os.WriteFile(filepath.Join(dir, "policy.rego"), []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := &RegoLinter{RegoConfig: RegoConfig{Policy: "policy.rego"}}
+
+	_, err := l.Expand(&Runner{WorkDir: dir})
+	if err == nil {
+		t.Fatal("expected error: got none")
+	}
+
+	if !strings.Contains(err.Error(), "rule deny must be a set of messages like `deny[msg] { ... }`") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package conflint
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Severity is the severity of a diagnostic
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a single linter error located at a specific line and column in a file.
+// EndLine and EndColumn point to the position right after the end of the located YAML node.
+type Diagnostic struct {
+	Linter string
+	Rule   string
+	// Severity is the severity of the diagnostic. An empty severity is turned into SeverityError before reporting
+	Severity  Severity
+	File      string
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+	Message   string
+	// Approximate is true when the exact location is unknown and the diagnostic is located at the nearest known position instead,
+	// like the parent of a missing field
+	Approximate bool
+	// FailOnWarn is true when the diagnostic is a warning that should result in a non-zero exit code regardless of the result's FailOnWarn,
+	// like a warning from a nested linter entry with `failOnWarn: true`
+	FailOnWarn bool
+}
+
+// Result is the outcome of running a linter as configured in an entry of the config file
+type Result struct {
+	Linter string
+	// Name identifies the config entry, like `conftest[0]` for the first entry in the conftest section
+	Name string
+	// FailOnWarn is true when warnings from the linter should result in a non-zero exit code
+	FailOnWarn  bool
+	Files       []string
+	Diagnostics []Diagnostic
+}
+
+// Reporter writes results from all the linters to w in a specific output format
+type Reporter interface {
+	Report(w io.Writer, results []Result) error
+}
+
+var (
+	reportersMu sync.RWMutex
+	reporters   = map[string]func(r *Runner) Reporter{}
+)
+
+func init() {
+	RegisterReporter("efm", func(r *Runner) Reporter { return &ErrorformatReporter{Format: r.Errformat} })
+	RegisterReporter("sarif", func(r *Runner) Reporter { return &SARIFReporter{} })
+	RegisterReporter("checkstyle", func(r *Runner) Reporter { return &CheckstyleReporter{} })
+	RegisterReporter("junit", func(r *Runner) Reporter { return &JUnitReporter{} })
+	RegisterReporter("rdjson", func(r *Runner) Reporter { return &RDJSONReporter{} })
+	RegisterReporter("rdjsonl", func(r *Runner) Reporter { return &RDJSONReporter{JSONLines: true} })
+}
+
+// RegisterReporter makes the reporter available as the output format named `format`.
+// It replaces the reporter previously registered with the same name, if any.
+func RegisterReporter(format string, new func(r *Runner) Reporter) {
+	reportersMu.Lock()
+	defer reportersMu.Unlock()
+
+	reporters[format] = new
+}
+
+func (r *Runner) reporter() (Reporter, error) {
+	format := r.Format
+	if format == "" {
+		format = "efm"
+	}
+
+	reportersMu.RLock()
+	new, ok := reporters[format]
+	reportersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported output format %q", r.Format)
+	}
+
+	return new(r), nil
+}
+
+// ErrorformatReporter prints every diagnostic in a line formatted with an errorformat-like template,
+// so that it can be read by tools like reviewdog.
+// Diagnostics are printed in the order of file, line and column.
+type ErrorformatReporter struct {
+	Format string
+}
+
+func (e *ErrorformatReporter) Report(w io.Writer, results []Result) error {
+	var diags []Diagnostic
+
+	for _, res := range results {
+		diags = append(diags, res.Diagnostics...)
+	}
+
+	sortDiagnostics(diags)
+
+	for _, d := range diags {
+		if _, err := fmt.Fprintln(w, e.format(d)); err != nil {
+			return fmt.Errorf("printing %s: %w", d.Message, err)
+		}
+	}
+
+	return nil
+}
+
+// format replaces the placeholders in the format with the fields of the diagnostic.
+// %L and %C are the end line and column, which fall back to the start ones when the end is unknown.
+func (e *ErrorformatReporter) format(d Diagnostic) string {
+	endLine, endCol := d.EndLine, d.EndColumn
+	if endLine == 0 {
+		endLine, endCol = d.Line, d.Column
+	}
+
+	replacer := strings.NewReplacer(
+		"%m", d.Message,
+		"%f", d.File,
+		"%l", fmt.Sprintf("%d", d.Line),
+		"%c", fmt.Sprintf("%d", d.Column),
+		"%L", fmt.Sprintf("%d", endLine),
+		"%C", fmt.Sprintf("%d", endCol),
+		"%s", string(d.Severity),
+	)
+
+	return replacer.Replace(e.Format)
+}
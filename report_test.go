@@ -0,0 +1,29 @@
+package conflint
+
+import (
+	"testing"
+)
+
+func TestErrorformatReporterFormat(t *testing.T) {
+	testcases := []struct {
+		d    Diagnostic
+		want string
+	}{
+		{
+			d:    Diagnostic{Severity: SeverityError, File: "app1/nginx.deploy.yaml", Line: 15, Column: 11, EndLine: 18, EndColumn: 29, Message: "forbidden"},
+			want: "app1/nginx.deploy.yaml:15:11-18:29: error: forbidden",
+		},
+		{
+			d:    Diagnostic{Severity: SeverityWarning, File: "app1/nginx.deploy.yaml", Line: 1, Column: 1, Message: "deprecated"},
+			want: "app1/nginx.deploy.yaml:1:1-1:1: warning: deprecated",
+		},
+	}
+
+	efm := &ErrorformatReporter{Format: "%f:%l:%c-%L:%C: %s: %m"}
+
+	for _, tc := range testcases {
+		if got := efm.format(tc.d); got != tc.want {
+			t.Errorf("unexpected output: want %q, got %q", tc.want, got)
+		}
+	}
+}
@@ -1,20 +1,32 @@
 package conflint
 
 import (
-	"bufio"
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	yaml "gopkg.in/yaml.v3"
 )
 
+// Config is the config file limited to the conftest and kubeval sections.
+//
+// Deprecated: The config file can have sections for any linter registered via RegisterLinter, which Config can't represent.
+// Use Runner.Lint or Runner.LintResults to run the linters in the config file.
+type Config struct {
+	Conftest []ConftestConfig `yaml:"conftest"`
+	Kubeval  []KubevalConfig  `yaml:"kubeval"`
+}
+
 type Runner struct {
 	Output     io.Writer
 	ConfigFile string
@@ -22,338 +34,616 @@ type Runner struct {
 	WorkDir    string
 	Delim      string
 	LogLevel   string
+	// Timeout is the maximum duration of the whole run. No timeout when zero
+	Timeout time.Duration
+	// Concurrency is the maximum number of linter invocations to run in parallel. Defaults to GOMAXPROCS
+	Concurrency int
+	// FirstMatchOnly makes a jsonpath matching many nodes result in a diagnostic at the first node, rather than one diagnostic per node
+	FirstMatchOnly bool
+	// StrictPaths makes a jsonpath that has no node in the file an error that aborts the run.
+	// By default, the diagnostic is located at the nearest existing ancestor of the path and marked approximate
+	StrictPaths bool
+	// Anchor is the part of located YAML nodes that diagnostics start at. Defaults to AnchorValue.
+	// It can be overridden per config entry via `anchor`, and per policy message via a jsonpath suffix like `@key`
+	Anchor Anchor
+	// Format is the name of the output format.
+	// One of "efm" (default), "sarif", "checkstyle", "junit", "rdjson", "rdjsonl" and formats registered via RegisterReporter
+	Format string
 }
 
-type Config struct {
-	Conftest []ConftestConfig `yaml:"conftest"`
-	Kubeval  []KubevalConfig  `yaml:"kubeval"`
-}
+// Run runs all the configured linters, prints the results in the configured format,
+// and returns an error when any linter error is found
+func (r *Runner) Run(ctx context.Context) error {
+	reporter, err := r.reporter()
+	if err != nil {
+		return err
+	}
 
-type ConftestConfig struct {
-	Files         []string `yaml:"files"`
-	Policy        string   `yaml:"policy"`
-	Input         string   `yaml:"input"`
-	Combine       bool     `yaml:"combine"`
-	FailOnWarn    bool     `yaml:"failOnWarn"`
-	Data          []string `yaml:"data"`
-	AllNamespaces bool     `yaml:"allNamespaces`
-	Namespaces    []string `yaml:"namespaces"`
-}
+	results, err := r.LintResults(ctx)
+	if err != nil {
+		return err
+	}
 
-type KubevalConfig struct {
-	Files                   []string `yaml:"files"`
-	Strict                  bool     `yaml:"strict"`
-	SchemaLocations         []string `yaml:"schemaLocations"`
-	IgnoreMissingSchemas    bool     `yaml:"ignoreMissingSchemas"`
-	IgnoredFilenamePatterns []string `yaml:"ignoredFilenamePatterns`
-	SkipKinds               []string `yaml:"skipKinds"`
-}
+	if err := reporter.Report(r.Output, results); err != nil {
+		return fmt.Errorf("reporting results: %w", err)
+	}
 
-type KubevalOutput = []KubevalFileResult
+	var errors, warnings int
 
-type KubevalFileResult struct {
-	Filename string   `yaml:"filename"`
-	Kind     string   `yaml:"kind"`
-	Status   string   `yaml:"status"`
-	Errors   []string `yaml:"errors"`
-}
+	var failed bool
+
+	for _, res := range results {
+		for _, d := range res.Diagnostics {
+			switch d.Severity {
+			case SeverityWarning:
+				warnings++
 
-type ConftestOutput = []ConftestFileResult
+				failed = failed || res.FailOnWarn || d.FailOnWarn
+			default:
+				errors++
+
+				failed = true
+			}
+		}
+	}
+
+	if failed {
+		return fmt.Errorf("found %s", summarize(errors, warnings))
+	} else if warnings > 0 {
+		log.Printf("found %s", summarize(errors, warnings))
+	}
 
-type ConftestFileResult struct {
-	Filename string           `yaml:"filename"`
-	Warnings []ConftestResult `yaml:"warnings"`
-	Failures []ConftestResult `yaml:"failures"`
+	return nil
 }
 
-type ConftestResult struct {
-	Msg string `yaml:"msg"`
+// Lint runs all the configured linters and returns located diagnostics from them, without printing anything
+func (r *Runner) Lint(ctx context.Context) ([]Diagnostic, error) {
+	results, err := r.LintResults(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var diags []Diagnostic
+
+	for _, res := range results {
+		diags = append(diags, res.Diagnostics...)
+	}
+
+	sortDiagnostics(diags)
+
+	return diags, nil
 }
 
-func (r *Runner) Run() error {
-	var config Config
+// LintResults is the same as Lint, except that it returns diagnostics grouped by config entries along with the linted files
+func (r *Runner) LintResults(ctx context.Context) ([]Result, error) {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
 
 	file := filepath.Join(r.WorkDir, r.ConfigFile)
 	bs, err := ioutil.ReadFile(file)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := yaml.Unmarshal(bs, &config); err != nil {
-		return err
+	entries, err := decodeConfig(bs)
+	if err != nil {
+		return nil, err
 	}
 
-	var output int
+	return r.lintEntries(ctx, entries)
+}
+
+// lintEntries runs the linters in the config entries and returns the results in the order of the entries
+func (r *Runner) lintEntries(ctx context.Context, entries []*linterEntry) ([]Result, error) {
+	var jobs []*job
 
-	if len(config.Conftest) > 0 {
-		_, err := exec.LookPath("conftest")
+	for _, e := range entries {
+		groups, err := e.linter.Expand(r)
 		if err != nil {
-			return fmt.Errorf("looking for executable: \"conftest\" not found in PATH")
+			return nil, fmt.Errorf("%s: %w", e.result.Name, err)
+		}
+
+		for _, files := range groups {
+			e.result.Files = append(e.result.Files, files...)
+
+			jobs = append(jobs, &job{entry: e, files: files})
 		}
 	}
 
-	for _, ct := range config.Conftest {
-		for _, fp := range ct.Files {
-			files, err := filepath.Glob(filepath.Join(r.WorkDir, fp))
-			if err != nil {
-				return fmt.Errorf("searching files matching %s: %w", fp, err)
-			}
+	if err := r.runJobs(ctx, jobs); err != nil {
+		return nil, err
+	}
 
-			var fs []string
+	for _, j := range jobs {
+		j.entry.result.Diagnostics = append(j.entry.result.Diagnostics, j.diags...)
+	}
 
-			for _, f := range files {
-				f = strings.TrimPrefix(f, r.WorkDir)
-				f = strings.TrimPrefix(f, "/")
+	var results []Result
 
-				fs = append(fs, f)
-			}
+	for _, e := range entries {
+		sortDiagnostics(e.result.Diagnostics)
 
-			args := []string{"test"}
-			args = append(args, fs...)
-			args = append(args, "-p", ct.Policy, "-o", "json")
+		results = append(results, e.result)
+	}
 
-			if ct.Input != "" {
-				args = append(args, "-i", ct.Input)
-			}
-			if ct.Combine {
-				args = append(args, "--combine")
-			}
-			if ct.AllNamespaces {
-				args = append(args, "--all-namespaces")
-			}
-			if len(ct.Data) > 0 {
-				args = append(args, "--data", strings.Join(ct.Data, ","))
-			}
-			if len(ct.Namespaces) > 0 {
-				args = append(args, "--namespace", strings.Join(ct.Namespaces, ","))
-			}
+	return results, nil
+}
 
-			cmd := exec.Command("conftest", args...)
-			cmd.Dir = r.WorkDir
-			out, err := cmd.CombinedOutput()
-			if err != nil && r.LogLevel == "DEBUG" {
-				fmt.Fprintf(os.Stderr, "DEBUG: running conftest %s: %v\n", strings.Join(args, " "), err)
-			}
+// job is a single invocation of a linter against a group of files
+type job struct {
+	entry *linterEntry
+	files []string
+	diags []Diagnostic
+}
 
-			var conftestOut ConftestOutput
+// runJobs runs the jobs with up to Concurrency workers.
+// It stops starting new jobs and returns the error once any job fails.
+func (r *Runner) runJobs(ctx context.Context, jobs []*job) error {
+	concurrency := r.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
 
-			if err := yaml.Unmarshal(out, &conftestOut); err != nil {
-				return err
-			}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if firstErr == nil {
+			firstErr = err
+		}
+
+		cancel()
+	}
+
+	queue := make(chan *job)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
 
-			for _, res := range conftestOut {
-				handle := func(msg string) error {
-					sub := strings.SplitN(msg, r.Delim, 2)
-					if len(sub) > 1 {
-						line, col, err := getLineColFromJsonpathExpr(filepath.Join(r.WorkDir, res.Filename), "$."+sub[0])
-						if err != nil {
-							return fmt.Errorf("processing %s: %w", sub[0], err)
-						}
-						if err := r.Print(res.Filename, line, col, sub[1]); err != nil {
-							return fmt.Errorf("printing %s: %w", sub[1], err)
-						}
-						output++
-					} else {
-						log.Printf("ignoring unsupported output: %s", msg)
-					}
-
-					return nil
+		go func() {
+			defer wg.Done()
+
+			for j := range queue {
+				if ctx.Err() != nil {
+					continue
 				}
 
-				for _, f := range res.Failures {
-					if err := handle(f.Msg); err != nil {
-						return err
-					}
+				if err := r.runJob(ctx, j); err != nil {
+					fail(fmt.Errorf("%s: %w", j.entry.result.Name, err))
 				}
 			}
-		}
+		}()
 	}
 
-	if len(config.Kubeval) > 0 {
-		_, err := exec.LookPath("kubeval")
+	for _, j := range jobs {
+		queue <- j
+	}
+
+	close(queue)
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	return ctx.Err()
+}
+
+func (r *Runner) runJob(ctx context.Context, j *job) error {
+	if j.entry.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, j.entry.Timeout)
+		defer cancel()
+	}
+
+	if j.entry.Anchor != "" {
+		entryRunner := *r
+		entryRunner.Anchor = j.entry.Anchor
+
+		r = &entryRunner
+	}
+
+	var err error
+
+	switch l := j.entry.linter.(type) {
+	case DiagnosticsLinter:
+		j.diags, err = l.Lint(ctx, r, j.files)
+	case CommandLinter:
+		var out []byte
+
+		out, err = l.Exec(ctx, r, j.files)
 		if err != nil {
-			return fmt.Errorf("looking for executable: \"kubeval\" not found in PATH")
+			return err
 		}
+
+		j.diags, err = l.Diagnostics(r, j.files, out)
 	}
 
-	for _, ke := range config.Kubeval {
-		for _, fp := range ke.Files {
-			files, err := filepath.Glob(filepath.Join(r.WorkDir, fp))
-			if err != nil {
-				return fmt.Errorf("searching files matching %s: %w", fp, err)
-			}
+	// Linters registered by others may leave the severity empty, which would be invalid in some output formats
+	for i := range j.diags {
+		if j.diags[i].Severity == "" {
+			j.diags[i].Severity = SeverityError
+		}
+	}
 
-			for _, f := range files {
-				f = strings.TrimPrefix(f, r.WorkDir)
-				f = strings.TrimPrefix(f, "/")
+	return err
+}
 
-				args := []string{f, "-o", "json"}
-				if ke.Strict {
-					args = append(args, "--strict")
-				}
-				if ke.IgnoreMissingSchemas {
-					args = append(args, "--ignore-missing-schemas")
-				}
-				if len(ke.IgnoredFilenamePatterns) > 0 {
-					args = append(args, "--ignored-filename-patterns", strings.Join(ke.IgnoredFilenamePatterns, ","))
-				}
-				if len(ke.SkipKinds) > 0 {
-					args = append(args, "--skip-kinds", strings.Join(ke.SkipKinds, ","))
-				}
-				if len(ke.SchemaLocations) > 0 {
-					args = append(args, "--schema-location", ke.SchemaLocations[0])
+// sortDiagnostics sorts diagnostics by file, line and column, so that the output is stable regardless of concurrency
+func sortDiagnostics(diags []Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
 
-					if len(ke.SchemaLocations) > 1 {
-						args = append(args, "--additional-schema-locations", strings.Join(ke.SchemaLocations[1:], ","))
-					}
-				}
+		if a.File != b.File {
+			return a.File < b.File
+		}
 
-				cmd := exec.Command("kubeval", args...)
-				cmd.Dir = r.WorkDir
-				out, err := cmd.CombinedOutput()
-				if err != nil && r.LogLevel == "DEBUG" {
-					fmt.Fprintf(os.Stderr, "DEBUG: running kubeval %s: %v\n", strings.Join(args, " "), err)
-				}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+
+		return a.Column < b.Column
+	})
+}
+
+// summarize returns a summary of found diagnostics like "1 linter error and 2 warnings"
+func summarize(errors, warnings int) string {
+	plural := func(n int, word string) string {
+		if n > 1 {
+			return fmt.Sprintf("%d %ss", n, word)
+		}
+		return fmt.Sprintf("%d %s", n, word)
+	}
 
-				var effectiveLines []string
+	switch {
+	case errors > 0 && warnings > 0:
+		return fmt.Sprintf("%s and %s", plural(errors, "linter error"), plural(warnings, "warning"))
+	case warnings > 0:
+		return plural(warnings, "linter warning")
+	}
 
-				allLines := bufio.NewScanner(bytes.NewReader(out))
-				for allLines.Scan() {
-					line := allLines.Text()
+	return plural(errors, "linter error")
+}
 
-					if strings.HasPrefix(line, "WARN - Set to ignore missing schemas") {
+// Anchor is the part of a located YAML node that a diagnostic starts at
+type Anchor string
 
-					} else {
-						effectiveLines = append(effectiveLines, line)
-					}
-				}
+const (
+	// AnchorValue makes diagnostics start at the value, like `true` in `privileged: true`
+	AnchorValue Anchor = "value"
+	// AnchorKey makes diagnostics start at the key of the value, like `privileged` in `privileged: true`,
+	// or at the dash of a sequence item. Diagnostics still end at the end of the value
+	AnchorKey Anchor = "key"
+)
 
-				var conftestOut KubevalOutput
+// ParseAnchor returns the anchor named s, that is either `key` or `value`
+func ParseAnchor(s string) (Anchor, error) {
+	switch a := Anchor(s); a {
+	case AnchorKey, AnchorValue:
+		return a, nil
+	}
 
-				jsonDocText := []byte(strings.Join(effectiveLines, "\n"))
-				if err := yaml.Unmarshal(jsonDocText, &conftestOut); err != nil {
-					fmt.Fprintf(os.Stderr, "kubeeval failed with output:\n%s", string(out))
+	return "", fmt.Errorf("unsupported anchor %q: must be either %s or %s", s, AnchorKey, AnchorValue)
+}
 
-					return fmt.Errorf("unmarshalling yaml: %w", err)
-				}
+func (a *Anchor) UnmarshalYAML(node *yaml.Node) error {
+	parsed, err := ParseAnchor(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
 
-				for _, res := range conftestOut {
-					handle := func(msg string) error {
-						sub := strings.SplitN(msg, ": ", 2)
-						if len(sub) > 1 {
-							line, col, err := getLineColFromJsonpathExpr(filepath.Join(r.WorkDir, f), "$."+sub[0])
-							if err != nil {
-								return fmt.Errorf("processing %s: %w", sub[0], err)
-							}
-							if err := r.Print(f, line, col, sub[1]); err != nil {
-								return fmt.Errorf("printing %s: %w", sub[1], err)
-							}
-							output++
-						} else {
-							log.Printf("ignoring unsupported output: %s", msg)
-						}
-
-						return nil
-					}
-
-					for _, f := range res.Errors {
-						if err := handle(f); err != nil {
-							return err
-						}
-					}
-				}
+	*a = parsed
+
+	return nil
+}
+
+// splitAnchor splits the anchor suffix like `@key` from the jsonpath expression.
+// The anchor is empty when the expression has no suffix.
+func splitAnchor(jsonpathExpr string) (string, Anchor) {
+	for _, a := range []Anchor{AnchorKey, AnchorValue} {
+		if expr := strings.TrimSuffix(jsonpathExpr, "@"+string(a)); expr != jsonpathExpr {
+			return expr, a
+		}
+	}
+
+	return jsonpathExpr, ""
+}
+
+// Locate returns diagnostics located at the YAML nodes found at jsonpathExpr in the file, one per node, in the document order.
+// Only the first one is returned when FirstMatchOnly is set. The file is relative to WorkDir.
+// The caller is responsible for filling the linter, the rule, the severity and the message of every diagnostic.
+//
+// jsonpathExpr can end with `@key` or `@value` to override the runner's Anchor.
+func (r *Runner) Locate(file string, jsonpathExpr string) ([]Diagnostic, error) {
+	return r.LocateInDocument(file, DocumentSelector{}, jsonpathExpr)
+}
+
+// LocateInDocument is like Locate but searches only the documents matching the selector, like the resource a linter reported about.
+// All the documents are searched when none matched the selector.
+//
+// When no document has the path, the diagnostics are located at the nearest existing ancestors of the path and marked approximate,
+// unless StrictPaths is set. A single diagnostic is located at the top of the file when every document is empty.
+func (r *Runner) LocateInDocument(file string, doc DocumentSelector, jsonpathExpr string) ([]Diagnostic, error) {
+	var approximate bool
+
+	jsonpathExpr, anchor := splitAnchor(jsonpathExpr)
+	if anchor == "" {
+		anchor = r.Anchor
+	}
+
+	nodes, root, err := getNodesFromJsonpathExpr(filepath.Join(r.WorkDir, file), doc, jsonpathExpr)
+	if err != nil {
+		var unresolved *unresolvedPathError
+
+		if r.StrictPaths || !errors.As(err, &unresolved) {
+			return nil, err
+		}
+
+		if r.LogLevel == "DEBUG" {
+			fmt.Fprintf(os.Stderr, "DEBUG: locating at the nearest ancestor: %v\n", err)
+		}
+
+		if unresolved.root == nil {
+			return []Diagnostic{{File: file, Line: 1, Column: 1, Approximate: true}}, nil
+		}
+
+		nodes, root = unresolved.ancestors, unresolved.root
+		approximate = true
+	}
+
+	if r.FirstMatchOnly {
+		nodes = nodes[:1]
+	}
+
+	lines := readLines(filepath.Join(r.WorkDir, file))
+
+	var diags []Diagnostic
+
+	for _, node := range nodes {
+		d := locateNode(file, root, node, lines, anchor)
+		d.Approximate = approximate
+
+		diags = append(diags, d)
+	}
+
+	return diags, nil
+}
+
+// locateNode returns a diagnostic located at the node within the document root in the file.
+// lines are the lines of the file, used to compute the end of the node and the position of the dash of a sequence item
+func locateNode(file string, root, node *yaml.Node, lines []string, anchor Anchor) Diagnostic {
+	line, col := node.Line, node.Column
+	if anchor == AnchorKey {
+		line, col = keyStart(root, node, lines)
+	}
+
+	endLine, endCol := nodeEnd(node, lines)
+
+	return Diagnostic{
+		File:      file,
+		Line:      line,
+		Column:    col,
+		EndLine:   endLine,
+		EndColumn: endCol,
+	}
+}
+
+// pointerLocator locates JSON pointers reported by linters like kubeconform within documents in files, reading every file at most once.
+// As with Runner.Locate, a missing node is approximated by its nearest existing ancestor unless StrictPaths is set.
+type pointerLocator struct {
+	r     *Runner
+	docs  map[string][]yaml.Node
+	lines map[string][]string
+	// broken is the set of files that can't be read or parsed
+	broken map[string]bool
+}
+
+func newPointerLocator(r *Runner) *pointerLocator {
+	return &pointerLocator{r: r, docs: map[string][]yaml.Node{}, lines: map[string][]string{}, broken: map[string]bool{}}
+}
+
+// Locate returns a diagnostic located at the node at the JSON pointer within the first document matching the selector in the file.
+// The file is relative to the runner's WorkDir.
+// The diagnostic is located at the top of the file and marked approximate when no document matched,
+// or when the file can't be parsed, like a malformed manifest the linter reported an error about.
+func (l *pointerLocator) Locate(file string, sel DocumentSelector, ptr string) (Diagnostic, error) {
+	docs, ok := l.docs[file]
+	if !ok && !l.broken[file] {
+		res, err := ReadYAMLFiles(filepath.Join(l.r.WorkDir, file))
+		if err != nil {
+			if l.r.LogLevel == "DEBUG" {
+				fmt.Fprintf(os.Stderr, "DEBUG: locating at the top of %s: %v\n", file, err)
 			}
+
+			l.broken[file] = true
 		}
+
+		docs = res[filepath.Join(l.r.WorkDir, file)]
+
+		l.docs[file] = docs
+		l.lines[file] = readLines(filepath.Join(l.r.WorkDir, file))
 	}
 
-	if output > 0 {
-		var word string
-		if output > 1 {
-			word = "errors"
-		} else {
-			word = "error"
+	if l.broken[file] {
+		return Diagnostic{File: file, Line: 1, Column: 1, Approximate: true}, nil
+	}
+
+	var root *yaml.Node
+
+	for i := range docs {
+		if !isEmptyDocument(&docs[i]) && sel.Matches(i, &docs[i]) {
+			root = docs[i].Content[0]
+
+			break
 		}
-		return fmt.Errorf("found %d linter %s", output, word)
 	}
 
-	return nil
+	if root == nil {
+		if l.r.StrictPaths {
+			return Diagnostic{}, fmt.Errorf("no document matching %+v found in %s", sel, file)
+		}
+
+		return Diagnostic{File: file, Line: 1, Column: 1, Approximate: true}, nil
+	}
+
+	path, err := parseJSONPointer(ptr)
+	if err != nil {
+		return Diagnostic{}, fmt.Errorf("processing %s: %w", ptr, err)
+	}
+
+	found, _, err := path.walk(root)
+	if err != nil && l.r.StrictPaths {
+		return Diagnostic{}, fmt.Errorf("processing %s: %w", ptr, err)
+	}
+
+	// A JSON pointer points to a single node
+	d := locateNode(file, root, found[0], l.lines[file], l.r.Anchor)
+	d.Approximate = err != nil
+
+	return d, nil
 }
 
-func getLineColFromJsonpathExpr(file string, jsonpathExpr string) (int, int, error) {
+// unresolvedPathError is returned when no document in the file has the path
+type unresolvedPathError struct {
+	// ancestors are the nearest existing ancestors of the path,
+	// within the first document having the longest leading part of the path
+	ancestors []*yaml.Node
+	// root is the top-level node of the document containing the ancestors, or nil when there's no non-empty document
+	root *yaml.Node
+	err  error
+}
+
+func (e *unresolvedPathError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unresolvedPathError) Unwrap() error {
+	return e.err
+}
+
+// getNodesFromJsonpathExpr returns the yaml nodes at jsonpathExpr within the first document in the file that matches the selector and has the path.
+// Empty documents are skipped. Documents after a syntax error are not searched, as the decoder can't recover from it.
+// The top-level node of the document is returned along with the nodes.
+// The error is an *unresolvedPathError when no document has the path.
+func getNodesFromJsonpathExpr(file string, sel DocumentSelector, jsonpathExpr string) ([]*yaml.Node, *yaml.Node, error) {
+	if jsonpathExpr == "" {
+		return nil, nil, fmt.Errorf("Expression must start with $, but got an empty string")
+	}
+
 	if jsonpathExpr[0] != '$' {
-		return 0, 0, fmt.Errorf("Expression must start with $, but got: %s", jsonpathExpr)
+		return nil, nil, fmt.Errorf("Expression must start with $, but got: %s", jsonpathExpr)
 	}
 
 	path, err := parseJsonpath(jsonpathExpr)
 	if err != nil {
-		return 0, 0, fmt.Errorf("parsing jsonpath %s: %w", jsonpathExpr, err)
+		return nil, nil, fmt.Errorf("parsing jsonpath %s: %w", jsonpathExpr, err)
 	}
 
 	f, err := os.Open(file)
 	if err != nil {
-		return 0, 0, fmt.Errorf("opening file %s: %w", file, err)
+		return nil, nil, fmt.Errorf("opening file %s: %w", file, err)
 	}
 
 	defer f.Close()
 
 	dec := yaml.NewDecoder(f)
 
-	next := func() (*yaml.Node, error) {
+	var (
+		selected   int
+		lastErr    error
+		unresolved *unresolvedPathError
+		depth      int
+	)
+
+	for index := 0; ; index++ {
 		doc := &yaml.Node{}
 
 		if err := dec.Decode(doc); err != nil {
-			if err == io.EOF {
-				return nil, nil
+			if err != io.EOF {
+				lastErr = fmt.Errorf("decoding yaml from %s: %w", file, err)
 			}
-			return nil, fmt.Errorf("decoding yaml from %s: %w", file, err)
+
+			break
 		}
 
-		if doc.Kind != yaml.DocumentNode {
-			panic(fmt.Errorf("the top-level yaml node must be a document node. got %v", doc.Kind))
+		if !sel.Matches(index, doc) {
+			continue
 		}
 
-		node := doc.Content[0]
+		selected++
 
-		if node.Kind != yaml.MappingNode {
-			panic(fmt.Errorf("the only yaml node in a document must be a mapping node. got %v", node.Kind))
+		if isEmptyDocument(doc) {
+			continue
 		}
 
-		got, err := path.Get(node)
-		if err != nil {
-			return nil, fmt.Errorf("getting node at %s: %w", jsonpathExpr, err)
+		root := doc.Content[0]
+
+		got, d, err := path.walk(root)
+		if err == nil {
+			return got, root, nil
 		}
 
-		return got, nil
+		if unresolved == nil || d > depth {
+			unresolved = &unresolvedPathError{
+				ancestors: got,
+				root:      root,
+				err:       fmt.Errorf("getting line and column numbers from %s: getting node at %s: %w", file, jsonpathExpr, err),
+			}
+			depth = d
+		}
 	}
 
-	var lastErr error
+	if selected == 0 && !sel.IsZero() {
+		// The linter may identify the resource differently from how it is written, like a kind missing from the file
+		return getNodesFromJsonpathExpr(file, DocumentSelector{}, jsonpathExpr)
+	}
 
-	for {
-		node, err := next()
-		if node != nil {
-			return node.Line, node.Column, nil
-		}
+	// A syntax error wins, as the path might be in the broken document
+	if lastErr != nil {
+		return nil, nil, fmt.Errorf("getting line and column numbers from %s: %w", file, lastErr)
+	}
 
-		if err == nil {
-			break
-		}
+	if unresolved != nil {
+		return nil, nil, unresolved
+	}
 
-		lastErr = err
+	// Every document is empty, so there's not even an ancestor
+	return nil, nil, &unresolvedPathError{
+		err: fmt.Errorf("getting line and column numbers from %s: no value found at %s", file, jsonpathExpr),
 	}
+}
 
-	if lastErr != nil {
-		return 0, 0, fmt.Errorf("getting line and column numbers from %s: %w", file, lastErr)
+// isEmptyDocument returns true when the document has no content, like the one between `---` and `---`, or the one having only comments
+func isEmptyDocument(doc *yaml.Node) bool {
+	if len(doc.Content) == 0 {
+		return true
 	}
 
-	return 0, 0, fmt.Errorf("gettling line and colum numbers from %s: no value found at %s", file, jsonpathExpr)
+	root := doc.Content[0]
+
+	return root.Kind == yaml.ScalarNode && root.Tag == "!!null" && root.Value == ""
 }
 
 func (r *Runner) Print(file string, line, col int, msg string) error {
-	// TODO maybe use https://github.com/phayes/checkstyle for additional checkstyle xml output?
-
-	replacer := strings.NewReplacer("%m", msg, "%f", file, "%l", fmt.Sprintf("%d", line), "%c", fmt.Sprintf("%d", col))
+	efm := &ErrorformatReporter{Format: r.Errformat}
 
-	printed := replacer.Replace(r.Errformat)
+	printed := efm.format(Diagnostic{File: file, Line: line, Column: col, Message: msg})
 
 	if _, err := r.Output.Write([]byte(printed)); err != nil {
 		return err
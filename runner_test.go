@@ -2,8 +2,11 @@ package conflint
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -11,15 +14,92 @@ import (
 
 func TestRunner(t *testing.T) {
 	testcases := []struct {
-		dir string
-		out string
-		err string
+		dir            string
+		firstMatchOnly bool
+		strictPaths    bool
+		out            string
+		err            string
 	}{
 		{
 			dir: "simple",
-			out: "app1/nginx.deploy.yaml:15:11: `privileged: true` is forbidden\n",
+			out: "app1/nginx.deploy.yaml:1:13: warning: Too old apiVersion. It must be apps/v1\n" +
+				"app1/nginx.deploy.yaml:15:11: error: `privileged: true` is forbidden\n",
+			err: "found 1 linter error and 1 warning",
+		},
+		{
+			dir: "rego",
+			out: "app1/broken.yaml:5:1: error: yaml: line 5: did not find expected node content\n" +
+				"app1/broken.yaml:5:1: error: yaml: line 5: did not find expected node content\n" +
+				"app1/nginx.deploy.yaml:1:13: warning: Too old apiVersion. It must be apps/v1\n" +
+				"app1/nginx.deploy.yaml:4:9: error: `hello` is reserved\n" +
+				"app1/nginx.deploy.yaml:15:11: error: `privileged: true` is forbidden\n",
+			err: "found 4 linter errors and 1 warning",
+		},
+		{
+			dir: "schema",
+			out: "app1/broken.yaml:5:1: error: yaml: line 5: did not find expected node content\n" +
+				"app1/resources.yaml:13:25: error: expected boolean or null, but got string\n" +
+				"app1/resources.yaml:20:9: error: expected integer, but got string\n" +
+				"app1/resources.yaml:23:7: error: no schema found for example.com/v1 Gadget in schemas\n",
+			err: "found 4 linter errors",
+		},
+		{
+			dir: "custom",
+			out: "app1/nginx.deploy.yaml:1:1: warning: apiVersion extensions/v1beta1 is deprecated\n" +
+				"app1/nginx.deploy.yaml:18:25: error: privileged containers are forbidden\n",
+			err: "found 1 linter error and 1 warning",
+		},
+		{
+			dir: "multiple-matches",
+			out: "app1/deploy.yaml:12:25: error: `privileged: true` is forbidden\n" +
+				"app1/deploy.yaml:18:25: error: `privileged: true` is forbidden\n",
+			err: "found 2 linter errors",
+		},
+		{
+			dir:            "multiple-matches",
+			firstMatchOnly: true,
+			out:            "app1/deploy.yaml:12:25: error: `privileged: true` is forbidden\n",
+			err:            "found 1 linter error",
+		},
+		{
+			dir: "multi-document",
+			out: "app1/resources.yaml:21:9: error: Service names must start with `svc-`\n",
+			err: "found 1 linter error",
+		},
+		{
+			dir: "non-mapping",
+			out: "app1/resources.yaml:4:15: error: `privileged: true` is forbidden\n" +
+				"app1/resources.yaml:6:1: error: the document must be a Kubernetes resource\n",
+			err: "found 2 linter errors",
+		},
+		{
+			dir: "empty-documents",
+			out: "app1/cm.yaml:4:3: error: labels are required\n" +
+				"app1/empty.yaml:1:1: error: labels are required\n",
+			err: "found 2 linter errors",
+		},
+		{
+			dir: "missing-field",
+			out: "app1/nginx.deploy.yaml:14:7: error: `runAsNonRoot: true` is required\n",
 			err: "found 1 linter error",
 		},
+		{
+			dir:         "missing-field",
+			strictPaths: true,
+			err:         "rego[0]: processing spec.template.spec.securityContext.runAsNonRoot: getting line and column numbers from testdata/missing-field/app1/nginx.deploy.yaml: getting node at $.spec.template.spec.securityContext.runAsNonRoot: evaluating jsonpath `.securityContext` at 3: mapping at line 14 does not have child named securityContext",
+		},
+		{
+			dir: "anchor",
+			out: "app1/nginx.deploy.yaml:1:13: warning: Too old apiVersion. It must be apps/v1\n" +
+				"app1/nginx.deploy.yaml:15:9: error: resources are required\n" +
+				"app1/nginx.deploy.yaml:18:13: error: `privileged: true` is forbidden\n",
+			err: "found 2 linter errors and 1 warning",
+		},
+		{
+			dir: "conftest-warn",
+			out: "app1/nginx.deploy.yaml:1:13: warning: Too old apiVersion. It must be apps/v1\n",
+			err: "found 1 linter warning",
+		},
 	}
 
 	for i := range testcases {
@@ -28,14 +108,16 @@ func TestRunner(t *testing.T) {
 			buf := &bytes.Buffer{}
 
 			runner := &Runner{
-				Output:     buf,
-				WorkDir:    filepath.Join("testdata", tc.dir),
-				ConfigFile: "conflint.yaml",
-				Errformat:  "%f:%l:%c: %m",
-				Delim:      ": ",
+				Output:         buf,
+				WorkDir:        filepath.Join("testdata", tc.dir),
+				ConfigFile:     "conflint.yaml",
+				Errformat:      "%f:%l:%c: %s: %m",
+				Delim:          ": ",
+				FirstMatchOnly: tc.firstMatchOnly,
+				StrictPaths:    tc.strictPaths,
 			}
 
-			err := runner.Run()
+			err := runner.Run(context.Background())
 
 			if err != nil {
 				if tc.err == "" {
@@ -55,3 +137,125 @@ func TestRunner(t *testing.T) {
 		})
 	}
 }
+
+func TestLint(t *testing.T) {
+	runner := &Runner{
+		WorkDir:    filepath.Join("testdata", "registry"),
+		ConfigFile: "conflint.yaml",
+		Delim:      ": ",
+	}
+
+	diags, err := runner.Lint(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Diagnostic{
+		{
+			Linter:    "echo",
+			Severity:  SeverityWarning,
+			File:      "app1/nginx.deploy.yaml",
+			Line:      4,
+			Column:    9,
+			EndLine:   4,
+			EndColumn: 14,
+			Message:   "name must be prefixed with the team name",
+		},
+	}
+
+	if diff := cmp.Diff(want, diags); diff != "" {
+		t.Errorf("unexpected diagnostics: %s", diff)
+	}
+}
+
+func TestLintConcurrency(t *testing.T) {
+	var want []Diagnostic
+
+	for _, concurrency := range []int{1, 2, 8} {
+		runner := &Runner{
+			WorkDir:     filepath.Join("testdata", "parallel"),
+			ConfigFile:  "conflint.yaml",
+			Delim:       ": ",
+			Concurrency: concurrency,
+		}
+
+		diags, err := runner.Lint(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error with concurrency %d: %v", concurrency, err)
+		}
+
+		if len(diags) != 6 {
+			t.Fatalf("unexpected number of diagnostics with concurrency %d: want 6, got %d", concurrency, len(diags))
+		}
+
+		if diags[0].File != "app1/a.yaml" || diags[0].Line != 2 || diags[5].File != "app1/c.yaml" || diags[5].Line != 4 {
+			t.Errorf("diagnostics are not sorted by file and line with concurrency %d: %+v", concurrency, diags)
+		}
+
+		if want == nil {
+			want = diags
+		} else if diff := cmp.Diff(want, diags); diff != "" {
+			t.Errorf("unexpected diagnostics with concurrency %d: %s", concurrency, diff)
+		}
+	}
+}
+
+func TestLocateInvalidYAML(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "invalid.yaml"), []byte("a: 1\n---\nb: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := &Runner{WorkDir: dir}
+
+	if _, err := runner.Locate("invalid.yaml", "$.b"); err == nil || !strings.Contains(err.Error(), "decoding yaml") {
+		t.Errorf("expected decoding error, got %v", err)
+	}
+
+	ds, err := runner.Locate("invalid.yaml", "$.a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(ds) != 1 || ds[0].Line != 1 || ds[0].Column != 4 {
+		t.Errorf("unexpected diagnostics: %+v", ds)
+	}
+}
+
+func TestLocateEmptyExpression(t *testing.T) {
+	runner := &Runner{WorkDir: filepath.Join("testdata", "simple")}
+
+	for _, expr := range []string{"", "@key", "@value"} {
+		if _, err := runner.Locate("app1/nginx.deploy.yaml", expr); err == nil || !strings.Contains(err.Error(), "empty string") {
+			t.Errorf("expected error for %q, got %v", expr, err)
+		}
+	}
+}
+
+func TestLocateEmptyDocuments(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "empty.yaml"), []byte("---\n# only comments\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := &Runner{WorkDir: dir}
+
+	ds, err := runner.Locate("empty.yaml", "$.metadata.name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Diagnostic{{File: "empty.yaml", Line: 1, Column: 1, Approximate: true}}
+
+	if d := cmp.Diff(want, ds); d != "" {
+		t.Errorf("unexpected diagnostics: want (-), got (+):\n%s", d)
+	}
+
+	runner.StrictPaths = true
+
+	if _, err := runner.Locate("empty.yaml", "$.metadata.name"); err == nil || !strings.Contains(err.Error(), "no value found at $.metadata.name") {
+		t.Errorf("expected error, got %v", err)
+	}
+}
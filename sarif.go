@@ -0,0 +1,166 @@
+package conflint
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+var linterInformationURIs = map[string]string{
+	"conftest":    "https://github.com/open-policy-agent/conftest",
+	"kubeval":     "https://github.com/instrumenta/kubeval",
+	"kubeconform": "https://github.com/yannh/kubeconform",
+	"yamllint":    "https://github.com/adrienverge/yamllint",
+}
+
+// SARIFReporter prints a SARIF 2.1.0 log containing a run per linter,
+// so that the results can be uploaded to GitHub code scanning or any other SARIF viewer
+type SARIFReporter struct {
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	// Properties is the property bag for conflint-specific information, omitted when there is none
+	Properties *sarifResultProperties `json:"properties,omitempty"`
+}
+
+type sarifResultProperties struct {
+	// Approximate is true when the result is located at the nearest known position, like the parent of a missing field
+	Approximate bool `json:"approximate"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine,omitempty"`
+	// EndColumn is the column right after the end of the region, as with Diagnostic
+	EndColumn int `json:"endColumn,omitempty"`
+}
+
+func (s *SARIFReporter) Report(w io.Writer, results []Result) error {
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{},
+	}
+
+	runIndices := map[string]int{}
+	ruleIndices := map[string]map[string]int{}
+
+	for _, res := range results {
+		i, ok := runIndices[res.Linter]
+		if !ok {
+			i = len(log.Runs)
+			runIndices[res.Linter] = i
+			ruleIndices[res.Linter] = map[string]int{}
+
+			log.Runs = append(log.Runs, sarifRun{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           res.Linter,
+						InformationURI: linterInformationURIs[res.Linter],
+						Rules:          []sarifRule{},
+					},
+				},
+				Results: []sarifResult{},
+			})
+		}
+
+		run := &log.Runs[i]
+
+		for _, d := range res.Diagnostics {
+			ruleIndex, ok := ruleIndices[res.Linter][d.Rule]
+			if !ok {
+				ruleIndex = len(run.Tool.Driver.Rules)
+				ruleIndices[res.Linter][d.Rule] = ruleIndex
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: d.Rule})
+			}
+
+			var props *sarifResultProperties
+			if d.Approximate {
+				props = &sarifResultProperties{Approximate: true}
+			}
+
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    d.Rule,
+				RuleIndex: ruleIndex,
+				Level:     string(d.Severity),
+				Message:   sarifMessage{Text: d.Message},
+				Locations: []sarifLocation{
+					{
+						PhysicalLocation: sarifPhysicalLocation{
+							ArtifactLocation: sarifArtifactLocation{
+								URI:       filepath.ToSlash(d.File),
+								URIBaseID: "%SRCROOT%",
+							},
+							Region: sarifRegion{
+								StartLine:   d.Line,
+								StartColumn: d.Column,
+								EndLine:     d.EndLine,
+								EndColumn:   d.EndColumn,
+							},
+						},
+					},
+				},
+				Properties: props,
+			})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(log)
+}
@@ -0,0 +1,101 @@
+package conflint
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSARIFReporter(t *testing.T) {
+	results := []Result{
+		{
+			Linter: "conftest",
+			Files:  []string{"app1/nginx.deploy.yaml"},
+			Diagnostics: []Diagnostic{
+				{
+					Linter:    "conftest",
+					Rule:      "deny",
+					Severity:  SeverityError,
+					File:      "app1/nginx.deploy.yaml",
+					Line:      15,
+					Column:    11,
+					EndLine:   15,
+					EndColumn: 15,
+					Message:   "`privileged: true` is forbidden",
+				},
+			},
+		},
+		{
+			Linter: "kubeval",
+			Files:  []string{"app1/nginx.deploy.yaml"},
+		},
+	}
+
+	want := `{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "conftest",
+          "informationUri": "https://github.com/open-policy-agent/conftest",
+          "rules": [
+            {
+              "id": "deny"
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "deny",
+          "ruleIndex": 0,
+          "level": "error",
+          "message": {
+            "text": "` + "`privileged: true` is forbidden" + `"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "app1/nginx.deploy.yaml",
+                  "uriBaseId": "%SRCROOT%"
+                },
+                "region": {
+                  "startLine": 15,
+                  "startColumn": 11,
+                  "endLine": 15,
+                  "endColumn": 15
+                }
+              }
+            }
+          ]
+        }
+      ]
+    },
+    {
+      "tool": {
+        "driver": {
+          "name": "kubeval",
+          "informationUri": "https://github.com/instrumenta/kubeval",
+          "rules": []
+        }
+      },
+      "results": []
+    }
+  ]
+}
+`
+
+	buf := &bytes.Buffer{}
+
+	if err := (&SARIFReporter{}).Report(buf, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("unexpected output: %s", diff)
+	}
+}
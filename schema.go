@@ -0,0 +1,311 @@
+package conflint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	yaml "gopkg.in/yaml.v3"
+)
+
+type SchemaConfig struct {
+	EntryConfig `yaml:",inline"`
+
+	Files []string `yaml:"files"`
+	// SchemaLocations is the list of local directories to search for JSON schemas, in the order of precedence
+	SchemaLocations      []string `yaml:"schemaLocations"`
+	IgnoreMissingSchemas bool     `yaml:"ignoreMissingSchemas"`
+	SkipKinds            []string `yaml:"skipKinds"`
+	// Draft is the JSON schema draft used for schemas without `$schema`, that is one of 4, 6, 7, 2019-09 and 2020-12.
+	// Defaults to 4, the draft of the schemas generated from Kubernetes OpenAPI specs.
+	// Schemas declaring `$schema`, like most CRD schemas, are compiled with the declared draft regardless of this
+	Draft string `yaml:"draft"`
+}
+
+// SchemaLinter validates every YAML document in the files against the JSON schema for the document's apiVersion and kind.
+//
+// Schemas are searched in SchemaLocations by file names used by
+// https://github.com/instrumenta/kubernetes-json-schema, like `deployment-apps-v1.json` and `service-v1.json`,
+// and by https://github.com/datreeio/CRDs-catalog, like `monitoring.coreos.com/servicemonitor_v1.json`.
+type SchemaLinter struct {
+	SchemaConfig
+
+	draft *jsonschema.Draft
+
+	mu      sync.Mutex
+	schemas map[string]*jsonschema.Schema
+}
+
+// SchemaViolation is a problem found in a YAML document
+type SchemaViolation struct {
+	// InstanceLocation is the JSON pointer to the invalid value within the document
+	InstanceLocation string
+	Rule             string
+	Message          string
+}
+
+func (s *SchemaLinter) DecodeConfig(node *yaml.Node) error {
+	if err := node.Decode(&s.SchemaConfig); err != nil {
+		return err
+	}
+
+	draft, err := schemaDraft(s.Draft)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+
+	s.draft = draft
+
+	return nil
+}
+
+// schemaDraft returns the JSON schema draft named like `7` or `2020-12`. An empty name is draft 4
+func schemaDraft(name string) (*jsonschema.Draft, error) {
+	switch name {
+	case "", "4":
+		return jsonschema.Draft4, nil
+	case "6":
+		return jsonschema.Draft6, nil
+	case "7":
+		return jsonschema.Draft7, nil
+	case "2019-09":
+		return jsonschema.Draft2019, nil
+	case "2020-12":
+		return jsonschema.Draft2020, nil
+	}
+
+	return nil, fmt.Errorf("unsupported draft %q: must be one of 4, 6, 7, 2019-09 and 2020-12", name)
+}
+
+func (s *SchemaLinter) Expand(r *Runner) ([][]string, error) {
+	return globGroups(r.WorkDir, s.Files, true)
+}
+
+func (s *SchemaLinter) Lint(ctx context.Context, r *Runner, files []string) ([]Diagnostic, error) {
+	var diags []Diagnostic
+
+	locator := newPointerLocator(r)
+
+	for _, f := range files {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, &TimeoutError{Command: fmt.Sprintf("validating %s against json schemas", f)}
+		}
+
+		docs, err := ReadYAMLFiles(filepath.Join(r.WorkDir, f))
+		if err != nil {
+			diags = append(diags, parseErrorDiagnostic("schema", f, err))
+
+			continue
+		}
+
+		for _, nodes := range docs {
+			for i, doc := range nodes {
+				if isEmptyDocument(&doc) {
+					continue
+				}
+
+				vs, err := s.validate(r, doc.Content[0])
+				if err != nil {
+					return nil, fmt.Errorf("validating document %d in %s: %w", i, f, err)
+				}
+
+				for _, v := range vs {
+					index := i
+
+					d, err := locator.Locate(f, DocumentSelector{Index: &index}, v.InstanceLocation)
+					if err != nil {
+						return nil, err
+					}
+
+					d.Linter = "schema"
+					d.Rule = v.Rule
+					d.Severity = SeverityError
+					d.Message = v.Message
+
+					diags = append(diags, d)
+				}
+			}
+		}
+	}
+
+	return diags, nil
+}
+
+// validate validates the document and returns the violations
+func (s *SchemaLinter) validate(r *Runner, doc *yaml.Node) ([]SchemaViolation, error) {
+	var header struct {
+		APIVersion string `yaml:"apiVersion"`
+		Kind       string `yaml:"kind"`
+	}
+
+	if doc.Kind != yaml.MappingNode {
+		return []SchemaViolation{
+			{Rule: "missing-kind", Message: "the document must be a mapping having apiVersion and kind to find the schema"},
+		}, nil
+	}
+
+	if err := doc.Decode(&header); err != nil {
+		return nil, err
+	}
+
+	if header.APIVersion == "" || header.Kind == "" {
+		return []SchemaViolation{
+			{Rule: "missing-kind", Message: "apiVersion and kind are required to find the schema"},
+		}, nil
+	}
+
+	for _, k := range s.SkipKinds {
+		if k == header.Kind {
+			return nil, nil
+		}
+	}
+
+	schema, err := s.schema(r, header.APIVersion, header.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if schema == nil {
+		if s.IgnoreMissingSchemas {
+			return nil, nil
+		}
+
+		return []SchemaViolation{
+			{
+				InstanceLocation: "/kind",
+				Rule:             "missing-schema",
+				Message:          fmt.Sprintf("no schema found for %s %s in %s", header.APIVersion, header.Kind, strings.Join(s.SchemaLocations, ", ")),
+			},
+		}, nil
+	}
+
+	var v interface{}
+
+	if err := doc.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	// Round-trip through JSON so that the value consists only of types the validator understands
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var instance interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.UseNumber()
+
+	if err := dec.Decode(&instance); err != nil {
+		return nil, err
+	}
+
+	err = schema.Validate(instance)
+	if err == nil {
+		return nil, nil
+	}
+
+	var verr *jsonschema.ValidationError
+
+	if !errors.As(err, &verr) {
+		return nil, err
+	}
+
+	var violations []SchemaViolation
+
+	var walk func(e *jsonschema.ValidationError)
+
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			violations = append(violations, SchemaViolation{
+				InstanceLocation: e.InstanceLocation,
+				Rule:             "schema",
+				Message:          e.Message,
+			})
+
+			return
+		}
+
+		for _, c := range e.Causes {
+			walk(c)
+		}
+	}
+
+	walk(verr)
+
+	return violations, nil
+}
+
+// schema returns the compiled schema for the apiVersion and the kind, or nil if none found
+func (s *SchemaLinter) schema(r *Runner, apiVersion, kind string) (*jsonschema.Schema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := apiVersion + "/" + kind
+
+	if sch, ok := s.schemas[key]; ok {
+		return sch, nil
+	}
+
+	if s.schemas == nil {
+		s.schemas = map[string]*jsonschema.Schema{}
+	}
+
+	for _, loc := range s.SchemaLocations {
+		for _, name := range schemaFileNames(apiVersion, kind) {
+			file := filepath.Join(r.WorkDir, loc, name)
+
+			if _, err := os.Stat(file); err != nil {
+				continue
+			}
+
+			compiler := jsonschema.NewCompiler()
+			// The draft declared via `$schema` in the schema wins
+			compiler.Draft = s.draft
+			if compiler.Draft == nil {
+				compiler.Draft = jsonschema.Draft4
+			}
+
+			sch, err := compiler.Compile(file)
+			if err != nil {
+				return nil, fmt.Errorf("compiling schema %s: %w", file, err)
+			}
+
+			s.schemas[key] = sch
+
+			return sch, nil
+		}
+	}
+
+	s.schemas[key] = nil
+
+	return nil, nil
+}
+
+// schemaFileNames returns candidate file names of the schema for the apiVersion and the kind
+func schemaFileNames(apiVersion, kind string) []string {
+	kind = strings.ToLower(kind)
+
+	group, version := "", apiVersion
+	if i := strings.LastIndex(apiVersion, "/"); i >= 0 {
+		group, version = apiVersion[:i], apiVersion[i+1:]
+	}
+
+	if group == "" {
+		return []string{
+			fmt.Sprintf("%s-%s.json", kind, version),
+		}
+	}
+
+	return []string{
+		fmt.Sprintf("%s-%s-%s.json", kind, strings.Split(group, ".")[0], version),
+		filepath.Join(group, fmt.Sprintf("%s_%s.json", kind, version)),
+	}
+}
@@ -0,0 +1,157 @@
+package conflint
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestSchemaFileNames(t *testing.T) {
+	testcases := []struct {
+		apiVersion, kind string
+		want             []string
+	}{
+		{apiVersion: "v1", kind: "Service", want: []string{"service-v1.json"}},
+		{apiVersion: "apps/v1", kind: "Deployment", want: []string{"deployment-apps-v1.json", "apps/deployment_v1.json"}},
+		{
+			apiVersion: "monitoring.coreos.com/v1",
+			kind:       "ServiceMonitor",
+			want:       []string{"servicemonitor-monitoring-v1.json", "monitoring.coreos.com/servicemonitor_v1.json"},
+		},
+	}
+
+	for _, tc := range testcases {
+		if d := cmp.Diff(tc.want, schemaFileNames(tc.apiVersion, tc.kind)); d != "" {
+			t.Errorf("unexpected file names for %s %s: want (-), got (+):\n%s", tc.apiVersion, tc.kind, d)
+		}
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	testcases := []struct {
+		config string
+		doc    string
+		want   []SchemaViolation
+	}{
+		{
+			doc: "just a string",
+			want: []SchemaViolation{
+				{Rule: "missing-kind", Message: "the document must be a mapping having apiVersion and kind to find the schema"},
+			},
+		},
+		{
+			doc: "metadata:\n  name: hello\n",
+			want: []SchemaViolation{
+				{Rule: "missing-kind", Message: "apiVersion and kind are required to find the schema"},
+			},
+		},
+		{
+			doc: "apiVersion: example.com/v1\nkind: Gadget\n",
+			want: []SchemaViolation{
+				{InstanceLocation: "/kind", Rule: "missing-schema", Message: "no schema found for example.com/v1 Gadget in schemas"},
+			},
+		},
+		{
+			config: "ignoreMissingSchemas: true",
+			doc:    "apiVersion: example.com/v1\nkind: Gadget\n",
+		},
+		{
+			config: "skipKinds: [Deployment]",
+			doc:    "apiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: many\n",
+		},
+		{
+			// kubernetes-json-schema naming
+			doc: "apiVersion: apps/v1\nkind: Deployment\nmetadata: {}\nspec:\n  replicas: many\n",
+			want: []SchemaViolation{
+				{InstanceLocation: "/spec/replicas", Rule: "schema", Message: "expected integer, but got string"},
+			},
+		},
+		{
+			// CRDs-catalog naming
+			doc: "apiVersion: example.com/v1\nkind: Widget\nspec:\n  size: large\n",
+			want: []SchemaViolation{
+				{InstanceLocation: "/spec/size", Rule: "schema", Message: "expected integer, but got string"},
+			},
+		},
+		{
+			// The draft declared via $schema is used regardless of the configured draft
+			config: "schemaLocations: [drafts]",
+			doc:    "apiVersion: example.com/v1\nkind: Gizmo\nspec:\n  mode: unsafe\n",
+			want: []SchemaViolation{
+				{InstanceLocation: "/spec/mode", Rule: "schema", Message: "value must be \"safe\""},
+			},
+		},
+		{
+			// const doesn't exist in the default draft 4
+			config: "schemaLocations: [drafts]",
+			doc:    "apiVersion: example.com/v1\nkind: Doohickey\nspec:\n  mode: unsafe\n",
+		},
+		{
+			config: "{schemaLocations: [drafts], draft: '7'}",
+			doc:    "apiVersion: example.com/v1\nkind: Doohickey\nspec:\n  mode: unsafe\n",
+			want: []SchemaViolation{
+				{InstanceLocation: "/spec/mode", Rule: "schema", Message: "value must be \"safe\""},
+			},
+		},
+	}
+
+	runner := &Runner{WorkDir: filepath.Join("testdata", "schema")}
+
+	for i := range testcases {
+		tc := testcases[i]
+
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			config := tc.config
+			if config == "" {
+				config = "{}"
+			}
+
+			var node yaml.Node
+
+			if err := yaml.Unmarshal([]byte(config), &node); err != nil {
+				t.Fatal(err)
+			}
+
+			s := &SchemaLinter{}
+
+			if err := s.DecodeConfig(node.Content[0]); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(s.SchemaLocations) == 0 {
+				s.SchemaLocations = []string{"schemas"}
+			}
+
+			var doc yaml.Node
+
+			if err := yaml.Unmarshal([]byte(tc.doc), &doc); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := s.validate(runner, doc.Content[0])
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("unexpected violations: want (-), got (+):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestSchemaDecodeConfigUnsupportedDraft(t *testing.T) {
+	var node yaml.Node
+
+	if err := yaml.Unmarshal([]byte("draft: '5'"), &node); err != nil {
+		t.Fatal(err)
+	}
+
+	err := (&SchemaLinter{}).DecodeConfig(node.Content[0])
+	if err == nil || err.Error() != `line 1: unsupported draft "5": must be one of 4, 6, 7, 2019-09 and 2020-12` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
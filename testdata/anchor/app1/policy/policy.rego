@@ -0,0 +1,16 @@
+package main
+
+warn[msg] {
+  input.apiVersion == "extensions/v1beta1"
+  msg = "apiVersion@value: Too old apiVersion. It must be apps/v1"
+}
+
+deny[msg] {
+  input.spec.template.spec.containers[0].securityContext.privileged == true
+  msg = "spec.template.spec.containers[0].securityContext.privileged: `privileged: true` is forbidden"
+}
+
+deny[msg] {
+  not input.spec.template.spec.containers[0].resources
+  msg = "spec.template.spec.containers[0]: resources are required"
+}
@@ -0,0 +1,6 @@
+#!/bin/sh
+# A fake linter printing problems in various formats for each file
+for f in "$@"; do
+  echo "$f: \$.spec.template.spec.containers[0].securityContext.privileged | privileged containers are forbidden"
+  echo "$f:1:1:W: apiVersion extensions/v1beta1 is deprecated"
+done
@@ -0,0 +1,5 @@
+#!/bin/sh
+# A fake linter requiring labels in every file, including the ones without resources
+for f in "$@"; do
+  echo "$f: metadata.labels | labels are required"
+done
@@ -0,0 +1,13 @@
+package main
+
+warn[msg] {
+  input.kind == "Deployment"
+  input.apiVersion == "extensions/v1beta1"
+  msg = "apiVersion: Too old apiVersion. It must be apps/v1"
+}
+
+deny[msg] {
+  input.kind == "Deployment"
+  input.spec.template.spec.containers[i].securityContext.privileged == true
+  msg = sprintf("spec.template.spec.containers[%d].securityContext.privileged: `privileged: true` is forbidden", [i])
+}
@@ -0,0 +1,7 @@
+package main
+
+deny[msg] {
+  input.kind == "Deployment"
+  not input.spec.template.spec.securityContext.runAsNonRoot
+  msg = "spec.template.spec.securityContext.runAsNonRoot: `runAsNonRoot: true` is required"
+}
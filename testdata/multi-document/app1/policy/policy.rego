@@ -0,0 +1,7 @@
+package main
+
+deny[msg] {
+  input.kind == "Service"
+  not startswith(input.metadata.name, "svc-")
+  msg = "metadata.name: Service names must start with `svc-`"
+}
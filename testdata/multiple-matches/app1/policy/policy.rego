@@ -0,0 +1,7 @@
+package main
+
+deny[msg] {
+  input.kind == "Deployment"
+  input.spec.template.spec.containers[_].securityContext.privileged == true
+  msg = "spec.template.spec.containers[?(@.securityContext.privileged == true)].securityContext.privileged: `privileged: true` is forbidden"
+}
@@ -0,0 +1,11 @@
+package main
+
+deny[msg] {
+  input[i].privileged == true
+  msg = sprintf("[%d].privileged: `privileged: true` is forbidden", [i])
+}
+
+deny[msg] {
+  is_string(input)
+  msg = "kind: the document must be a Kubernetes resource"
+}
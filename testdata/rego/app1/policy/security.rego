@@ -0,0 +1,6 @@
+package security
+
+violation_name[{"msg": msg}] {
+  input.metadata.name == "hello"
+  msg = "metadata.name: `hello` is reserved"
+}
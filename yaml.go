@@ -6,6 +6,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	yaml "gopkg.in/yaml.v3"
 )
@@ -66,3 +70,369 @@ func ReadYAMLFiles(f string) (map[string][]yaml.Node, error) {
 
 	return res, nil
 }
+
+// yamlErrorLine matches the line number in errors from the YAML parser, like `yaml: line 5: did not find expected node content`
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+):`)
+
+// parseErrorDiagnostic returns an approximate diagnostic about the file that can't be parsed as YAML,
+// located at the line in the error if any, or at the top of the file
+func parseErrorDiagnostic(linter, file string, err error) Diagnostic {
+	line := 1
+
+	if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+		if l, convErr := strconv.Atoi(m[1]); convErr == nil && l > 0 {
+			line = l
+		}
+	}
+
+	return Diagnostic{
+		Linter:      linter,
+		Rule:        "parse",
+		Severity:    SeverityError,
+		File:        file,
+		Line:        line,
+		Column:      1,
+		Message:     err.Error(),
+		Approximate: true,
+	}
+}
+
+// readLines returns the lines of the file, or nil when the file can't be read
+func readLines(file string) []string {
+	bs, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+
+	return strings.Split(string(bs), "\n")
+}
+
+// nodeEnd returns the line and the column right after the end of the node.
+//
+// lines are the lines of the file containing the node, used to find the ends of scalars spanning many lines like block scalars,
+// and the closing brackets of flow collections.
+// The end is computed from the node alone when lines are nil, which is accurate only for single-line nodes.
+func nodeEnd(node *yaml.Node, lines []string) (int, int) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.MappingNode, yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			// An empty flow collection like `{}` or `[ ]`
+			if l, c, ok := closingBracket(lines, node.Line, node.Column+1); ok {
+				return l, c
+			}
+
+			return node.Line, node.Column + 2
+		}
+
+		line, col := nodeEnd(node.Content[len(node.Content)-1], lines)
+
+		if node.Style&yaml.FlowStyle != 0 {
+			if l, c, ok := closingBracket(lines, line, col); ok {
+				return l, c
+			}
+
+			// The closing bracket
+			col++
+		}
+
+		return line, col
+	case yaml.AliasNode:
+		return node.Line, node.Column + len("*") + utf8.RuneCountInString(node.Value)
+	}
+
+	var (
+		line, col int
+		ok        bool
+	)
+
+	switch {
+	case node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		line, col, ok = blockScalarEnd(node, lines)
+	case node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0:
+		line, col, ok = quotedScalarEnd(node, lines)
+	default:
+		line, col, ok = plainScalarEnd(node, lines)
+	}
+
+	if ok {
+		return line, col
+	}
+
+	width := utf8.RuneCountInString(node.Value)
+
+	if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		width += 2
+	}
+
+	return node.Line, node.Column + width
+}
+
+// keyStart returns the start of the key of the node within the root, or the start of the dash when the node is an item of a block sequence.
+// It returns the start of the node itself when the node has neither, like the root itself and an item of a flow sequence.
+func keyStart(root, node *yaml.Node, lines []string) (int, int) {
+	parent, i := findParent(root, node)
+
+	switch {
+	case parent == nil:
+	case parent.Kind == yaml.MappingNode && i%2 == 1:
+		key := parent.Content[i-1]
+
+		return key.Line, key.Column
+	case parent.Kind == yaml.SequenceNode && parent.Style&yaml.FlowStyle == 0:
+		text, ok := sourceLine(lines, node.Line)
+		if !ok {
+			break
+		}
+
+		for j := node.Column - 2; j >= 0 && j < len(text); j-- {
+			if text[j] == '-' {
+				return node.Line, j + 1
+			}
+
+			if text[j] != ' ' {
+				break
+			}
+		}
+	}
+
+	return node.Line, node.Column
+}
+
+// findParent returns the collection containing the node within the root, and the index of the node in the content of the collection.
+// It returns nil when the node is not in the root, or is the root itself.
+func findParent(root, node *yaml.Node) (*yaml.Node, int) {
+	for i, c := range root.Content {
+		if c == node {
+			return root, i
+		}
+	}
+
+	for _, c := range root.Content {
+		if p, i := findParent(c, node); p != nil {
+			return p, i
+		}
+	}
+
+	return nil, -1
+}
+
+// sourceLine returns the runes in the line, or false when the line is out of the lines
+func sourceLine(lines []string, line int) ([]rune, bool) {
+	if line < 1 || line > len(lines) {
+		return nil, false
+	}
+
+	return []rune(lines[line-1]), true
+}
+
+// indentation returns the number of the leading spaces in the line
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// closingBracket returns the position right after the closing bracket of a flow collection,
+// searching from the position right after the last item, skipping spaces, line breaks and a trailing comma
+func closingBracket(lines []string, line, col int) (int, int, bool) {
+	for l := line; l <= len(lines); l++ {
+		text, _ := sourceLine(lines, l)
+
+		start := 0
+		if l == line {
+			start = col - 1
+		}
+
+		for i := start; i >= 0 && i < len(text); i++ {
+			switch text[i] {
+			case ' ', '\t', ',':
+			case ']', '}':
+				return l, i + 2, true
+			default:
+				return 0, 0, false
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// blockScalarEnd returns the position right after the last non-empty line of the literal or folded block scalar.
+// The content of a block scalar is the lines following the header like `|-`, indented more than the line containing the header.
+func blockScalarEnd(node *yaml.Node, lines []string) (int, int, bool) {
+	header, ok := sourceLine(lines, node.Line)
+	if !ok || node.Column < 1 || node.Column > len(header) {
+		return 0, 0, false
+	}
+
+	parentIndent := indentation(lines[node.Line-1])
+
+	// The header itself is the end of a block scalar without content
+	line, col := node.Line, node.Column+1
+
+	for col-1 < len(header) && strings.ContainsRune("+-0123456789", header[col-1]) {
+		col++
+	}
+
+	indent := -1
+
+	for l := node.Line + 1; l <= len(lines); l++ {
+		text := strings.TrimRight(lines[l-1], " \t\r")
+
+		if text == "" {
+			continue
+		}
+
+		i := indentation(text)
+
+		if i <= parentIndent || (indent >= 0 && i < indent) {
+			break
+		}
+
+		if indent < 0 {
+			indent = i
+		}
+
+		line, col = l, utf8.RuneCountInString(text)+1
+	}
+
+	return line, col, true
+}
+
+// quotedScalarEnd returns the position right after the closing quote of the single or double quoted scalar, which may span many lines
+func quotedScalarEnd(node *yaml.Node, lines []string) (int, int, bool) {
+	text, ok := sourceLine(lines, node.Line)
+	if !ok || node.Column < 1 || node.Column > len(text) {
+		return 0, 0, false
+	}
+
+	quote := text[node.Column-1]
+	if quote != '"' && quote != '\'' {
+		return 0, 0, false
+	}
+
+	for l, start := node.Line, node.Column; l <= len(lines); l, start = l+1, 0 {
+		text, _ := sourceLine(lines, l)
+
+		for i := start; i < len(text); i++ {
+			switch {
+			case quote == '"' && text[i] == '\\':
+				// Skip the escaped character. An escaped line break continues the scalar on the next line
+				i++
+			case quote == '\'' && text[i] == '\'' && i+1 < len(text) && text[i+1] == '\'':
+				// An escaped single quote
+				i++
+			case text[i] == quote:
+				return l, i + 2, true
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// plainScalarEnd returns the position right after the plain scalar, which may be folded into many lines.
+// The lines of the scalar are consumed from the value until nothing is left.
+func plainScalarEnd(node *yaml.Node, lines []string) (int, int, bool) {
+	text, ok := sourceLine(lines, node.Line)
+	if !ok || node.Column < 1 || node.Column > len(text)+1 {
+		return 0, 0, false
+	}
+
+	rest := []rune(node.Value)
+
+	consume := func(seg []rune) bool {
+		if len(seg) > len(rest) || string(rest[:len(seg)]) != string(seg) {
+			return false
+		}
+
+		rest = []rune(strings.TrimLeft(string(rest[len(seg):]), " \n"))
+
+		return true
+	}
+
+	first := []rune(strings.TrimRight(string(text[node.Column-1:]), " \t\r"))
+	if len(first) > len(rest) {
+		// The rest of the line contains something following the scalar, like a comment or a flow indicator
+		first = first[:len(rest)]
+	}
+
+	if !consume(first) {
+		return 0, 0, false
+	}
+
+	line, col := node.Line, node.Column+len(first)
+
+	for l := node.Line + 1; len(rest) > 0 && l <= len(lines); l++ {
+		seg := strings.TrimSpace(lines[l-1])
+
+		if seg == "" {
+			continue
+		}
+
+		if !consume([]rune(seg)) {
+			return 0, 0, false
+		}
+
+		line, col = l, utf8.RuneCountInString(strings.TrimRight(lines[l-1], " \t\r"))+1
+	}
+
+	if len(rest) > 0 {
+		return 0, 0, false
+	}
+
+	return line, col, true
+}
+
+// DocumentSelector selects a document within a multi-document YAML file, like the one a linter reported a resource in.
+// Fields left empty match any document.
+type DocumentSelector struct {
+	// Index is the zero-based position of the document in the file
+	Index *int
+	// Kind, Namespace and Name are matched against `kind`, `metadata.namespace` and `metadata.name` of the document
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// IsZero returns true when the selector matches any document
+func (s DocumentSelector) IsZero() bool {
+	return s.Index == nil && s.Kind == "" && s.Namespace == "" && s.Name == ""
+}
+
+// Matches returns true when the document at the index in the file matches the selector
+func (s DocumentSelector) Matches(index int, doc *yaml.Node) bool {
+	if s.Index != nil && *s.Index != index {
+		return false
+	}
+
+	if s.Kind == "" && s.Namespace == "" && s.Name == "" {
+		return true
+	}
+
+	if doc.Kind == yaml.DocumentNode {
+		if len(doc.Content) == 0 {
+			return false
+		}
+
+		doc = doc.Content[0]
+	}
+
+	if doc.Kind != yaml.MappingNode {
+		return false
+	}
+
+	var header struct {
+		Kind     string `yaml:"kind"`
+		Metadata struct {
+			Namespace string `yaml:"namespace"`
+			Name      string `yaml:"name"`
+		} `yaml:"metadata"`
+	}
+
+	if err := doc.Decode(&header); err != nil {
+		return false
+	}
+
+	return (s.Kind == "" || s.Kind == header.Kind) &&
+		(s.Namespace == "" || s.Namespace == header.Metadata.Namespace) &&
+		(s.Name == "" || s.Name == header.Metadata.Name)
+}
@@ -0,0 +1,160 @@
+package conflint
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestNodeEnd(t *testing.T) {
+	testcases := []struct {
+		expr string
+		data string
+		line int
+		col  int
+	}{
+		{
+			expr: `$.foo.bar`,
+			data: `foo:
+  bar: 123
+`,
+			line: 2,
+			col:  11,
+		},
+		{
+			expr: `$.foo.bar`,
+			data: `foo:
+  bar: "123"
+`,
+			line: 2,
+			col:  13,
+		},
+		{
+			expr: `$.foo`,
+			data: `foo:
+  bar: 1
+  baz: abc
+`,
+			line: 3,
+			col:  11,
+		},
+		{
+			expr: `$.foo`,
+			data: `foo: {bar: 1, baz: [a, b]}
+`,
+			line: 1,
+			col:  27,
+		},
+		{
+			expr: `$.foo`,
+			data: `foo: []
+`,
+			line: 1,
+			col:  8,
+		},
+		{
+			expr: `$.foo`,
+			data: `foo: |
+  line 1
+
+  line 3
+bar: 1
+`,
+			line: 4,
+			col:  9,
+		},
+		{
+			expr: `$.foo[0].script`,
+			data: `foo:
+- script: >-
+    echo
+    hello
+  name: greet
+`,
+			line: 4,
+			col:  10,
+		},
+		{
+			expr: `$.foo`,
+			data: `foo: "a \"quoted\"
+  text"
+`,
+			line: 2,
+			col:  8,
+		},
+		{
+			expr: `$.foo`,
+			data: `foo: 'it''s' # comment
+`,
+			line: 1,
+			col:  13,
+		},
+		{
+			expr: `$.foo`,
+			data: `foo: a plain
+  multi-line text
+bar: 1
+`,
+			line: 2,
+			col:  18,
+		},
+		{
+			expr: `$.foo`,
+			data: `foo: [
+  a,
+  b,
+]
+`,
+			line: 4,
+			col:  2,
+		},
+		{
+			expr: `$.foo`,
+			data: `foo: { bar: 1 }
+`,
+			line: 1,
+			col:  16,
+		},
+		{
+			expr: `$.foo`,
+			data: `foo: héllo
+`,
+			line: 1,
+			col:  11,
+		},
+	}
+
+	for i := range testcases {
+		tc := testcases[i]
+
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			path, err := parseJsonpath(tc.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			root := yaml.Node{}
+
+			if err := yaml.Unmarshal([]byte(tc.data), &root); err != nil {
+				t.Fatal("bug: failed parsing yaml")
+			}
+
+			nodes, err := path.Get(root.Content[0])
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			line, col := nodeEnd(nodes[0], strings.Split(tc.data, "\n"))
+
+			if line != tc.line {
+				t.Errorf("unexpected line: want %v, got %v", tc.line, line)
+			}
+
+			if col != tc.col {
+				t.Errorf("unexpected column: want %v, got %v", tc.col, col)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package conflint
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"log"
+	"regexp"
+	"strconv"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+type YamllintConfig struct {
+	EntryConfig `yaml:",inline"`
+
+	Files []string `yaml:"files"`
+	// Config is the path to the yamllint config file, passed via `-c`
+	Config string `yaml:"config"`
+	// ConfigData is the yamllint config in YAML, like `{extends: relaxed}`, passed via `-d`
+	ConfigData string `yaml:"configData"`
+	// NoWarnings makes yamllint output only errors
+	NoWarnings bool `yaml:"noWarnings"`
+}
+
+// YamllintLinter runs yamllint once per file pattern in Files
+type YamllintLinter struct {
+	YamllintConfig
+}
+
+// yamllintParsableLine matches a line in the output of `yamllint -f parsable`, like
+// `app1/nginx.deploy.yaml:3:1: [warning] missing document start "---" (document-start)`
+var yamllintParsableLine = regexp.MustCompile(`^(.+?):(\d+):(\d+): \[(error|warning)\] (.*?)(?: \(([a-z-]+)\))?$`)
+
+func (y *YamllintLinter) DecodeConfig(node *yaml.Node) error {
+	return node.Decode(&y.YamllintConfig)
+}
+
+func (y *YamllintLinter) Expand(r *Runner) ([][]string, error) {
+	return globGroups(r.WorkDir, y.Files, false)
+}
+
+func (y *YamllintLinter) Exec(ctx context.Context, r *Runner, files []string) ([]byte, error) {
+	args := []string{"-f", "parsable"}
+	if y.Config != "" {
+		args = append(args, "-c", y.Config)
+	}
+	if y.ConfigData != "" {
+		args = append(args, "-d", y.ConfigData)
+	}
+	if y.NoWarnings {
+		args = append(args, "--no-warnings")
+	}
+	args = append(args, files...)
+
+	return execCommand(ctx, r, "yamllint", args...)
+}
+
+func (y *YamllintLinter) Diagnostics(r *Runner, files []string, out []byte) ([]Diagnostic, error) {
+	var diags []Diagnostic
+
+	lines := bufio.NewScanner(bytes.NewReader(out))
+	for lines.Scan() {
+		line := lines.Text()
+		if line == "" {
+			continue
+		}
+
+		m := yamllintParsableLine.FindStringSubmatch(line)
+		if m == nil {
+			log.Printf("ignoring unsupported output: %s", line)
+
+			continue
+		}
+
+		// yamllint reports positions as-is, so that there is no need to resolve any path
+		l, _ := strconv.Atoi(m[2])
+		c, _ := strconv.Atoi(m[3])
+
+		rule := m[6]
+		if rule == "" {
+			rule = "syntax"
+		}
+
+		diags = append(diags, Diagnostic{
+			Linter:   "yamllint",
+			Rule:     rule,
+			Severity: Severity(m[4]),
+			File:     m[1],
+			Line:     l,
+			Column:   c,
+			Message:  m[5],
+		})
+	}
+
+	return diags, nil
+}
@@ -0,0 +1,31 @@
+package conflint
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestYamllintDiagnostics(t *testing.T) {
+	out := `app1/nginx.deploy.yaml:1:1: [warning] missing document start "---" (document-start)
+app1/nginx.deploy.yaml:15:81: [error] line too long (92 > 80 characters) (line-length)
+app1/broken.yaml:3:1: [error] syntax error: could not find expected ':'
+`
+
+	y := &YamllintLinter{}
+
+	diags, err := y.Diagnostics(&Runner{}, []string{"app1/nginx.deploy.yaml", "app1/broken.yaml"}, []byte(out))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Diagnostic{
+		{Linter: "yamllint", Rule: "document-start", Severity: SeverityWarning, File: "app1/nginx.deploy.yaml", Line: 1, Column: 1, Message: `missing document start "---"`},
+		{Linter: "yamllint", Rule: "line-length", Severity: SeverityError, File: "app1/nginx.deploy.yaml", Line: 15, Column: 81, Message: "line too long (92 > 80 characters)"},
+		{Linter: "yamllint", Rule: "syntax", Severity: SeverityError, File: "app1/broken.yaml", Line: 3, Column: 1, Message: "syntax error: could not find expected ':'"},
+	}
+
+	if d := cmp.Diff(want, diags); d != "" {
+		t.Errorf("unexpected diagnostics: want (-), got (+):\n%s", d)
+	}
+}